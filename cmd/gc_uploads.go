@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+// runGCUploads menjalankan `tasktracker gc-uploads [-dry-run] [-grace 24h]`:
+// melaporkan file cover orphan dan cover task yang filenya hilang, lalu
+// menghapus orphan yang lebih tua dari grace.
+func runGCUploads(gc services.UploadGCService, grace time.Duration, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("gc-uploads", flag.ContinueOnError)
+	fs.SetOutput(out)
+	dryRun := fs.Bool("dry-run", false, "hanya tampilkan laporan, jangan hapus apa pun")
+	fs.DurationVar(&grace, "grace", grace, "umur minimum file orphan yang dihapus")
+	if err := fs.Parse(args); errors.Is(err, flag.ErrHelp) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return errors.New("penggunaan: tasktracker gc-uploads [-dry-run] [-grace 24h]")
+	}
+	if grace < 0 {
+		return errors.New("-grace tidak boleh negatif")
+	}
+
+	report, err := gc.Collect(context.Background(), services.UploadGCOptions{Grace: grace, DryRun: *dryRun})
+	if report == nil {
+		return err
+	}
+	deleted := make(map[string]bool, len(report.Deleted))
+	for _, key := range report.Deleted {
+		deleted[key] = true
+	}
+	now := time.Now()
+	for _, obj := range report.Orphans {
+		status := "dilewati, lebih baru dari grace"
+		switch {
+		case deleted[obj.Key] && *dryRun:
+			status = "akan dihapus"
+		case deleted[obj.Key]:
+			status = "dihapus"
+		}
+		fmt.Fprintf(out, "orphan   %s (%d byte, umur %s): %s\n", obj.Key, obj.Size, now.Sub(obj.ModTime).Round(time.Second), status)
+	}
+	for _, d := range report.Dangling {
+		fmt.Fprintf(out, "hilang   task %d: %s\n", d.TaskID, d.Path)
+	}
+	verb := "dihapus"
+	if *dryRun {
+		verb = "akan dihapus (dry-run)"
+	}
+	fmt.Fprintf(out, "%d file orphan, %d %s, %d cover task tanpa file\n",
+		len(report.Orphans), len(report.Deleted), verb, len(report.Dangling))
+	return err
+}
@@ -1,34 +1,202 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
-	"net/http"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/nabilulilalbab/welcomesite"
 	"github.com/nabilulilalbab/welcomesite/config"
 	"github.com/nabilulilalbab/welcomesite/controllers"
+	"github.com/nabilulilalbab/welcomesite/migrations"
 	"github.com/nabilulilalbab/welcomesite/repositories"
 	"github.com/nabilulilalbab/welcomesite/routes"
+	"github.com/nabilulilalbab/welcomesite/server"
 	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/storage"
+	"github.com/nabilulilalbab/welcomesite/utils"
 	"github.com/nabilulilalbab/welcomesite/view"
 )
 
 func main() {
-	config.InitDatabase()
+	cfg, args, err := config.Load(os.Args[1:], os.LookupEnv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Subcommand:
+	//   tasktracker [flag...] migrate up|down|status|to <versi>
+	//   tasktracker [flag...] useradd <username>
+	//   tasktracker [flag...] gc-uploads [-dry-run] [-grace 24h]
+	if len(args) > 0 {
+		if err := runCommand(cfg, args); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if err := config.InitDatabase(cfg.Database); err != nil {
+		log.Fatal(err)
+	}
 	cachedTemplates := view.ParseTemplates()
-	// Definisikan path untuk unggahan dan buat direktori jika belum ada
-	uploadsPath := "static/uploads/tasks"
-	// Task
+	covers, err := newCoverStorage(cfg.Uploads)
+	if err != nil {
+		log.Fatal(err)
+	}
+	workflow, err := config.LoadWorkflow(cfg.Workflow)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Task. Hub WebSocket menerima perubahan task dari service dan
+	// meneruskannya ke halaman yang sedang terbuka.
+	hub := controllers.NewHub()
 	taskRepo := repositories.NewTaskRepository(config.DB)
-	taskService := services.NewTaskService(taskRepo, uploadsPath)
-	taskCtrl := controllers.NewTaskController(taskService, cachedTemplates)
+	taskService := services.NewTaskService(taskRepo, cfg.Uploads.Dir,
+		services.WithWorkflow(workflow),
+		services.WithUploadOptions(services.UploadOptions{
+			MaxBytes:     cfg.Uploads.MaxBytes,
+			CoverWidth:   cfg.Uploads.CoverWidth,
+			MaxDimension: cfg.Uploads.MaxDimension,
+			MaxPixels:    cfg.Uploads.MaxPixels,
+		}),
+		services.WithStorage(covers),
+		services.WithNotifier(hub),
+		services.WithLaunchers(utils.NewLaunchers(cfg.Launchers)),
+	)
+	taskCtrl := controllers.NewTaskController(taskService, cachedTemplates, controllers.WithHub(hub))
+	gitStatus := services.NewGitStatusService(taskService, services.WithGitStatusTTL(cfg.Git.StatusTTL))
+	taskAPICtrl := controllers.NewTaskAPIController(taskService, controllers.WithGitStatus(gitStatus))
+	// ctx dibatalkan oleh SIGINT/SIGTERM dan memulai shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	retention := time.Duration(cfg.Trash.RetentionDays) * 24 * time.Hour
+	purgerDone := make(chan struct{})
+	go func() {
+		defer close(purgerDone)
+		services.RunTrashPurger(ctx, taskService, retention, time.Hour)
+	}()
+	uploadGCDone := make(chan struct{})
+	go func() {
+		defer close(uploadGCDone)
+		uploadGC := services.NewUploadGCService(taskRepo, covers)
+		services.RunUploadGC(ctx, uploadGC, cfg.Uploads.GCGrace, cfg.Uploads.GCInterval)
+	}()
+	gitRefresherDone := make(chan struct{})
+	go func() {
+		defer close(gitRefresherDone)
+		services.RunGitStatusRefresher(ctx, gitStatus, cfg.Git.RefreshInterval)
+	}()
+	// Tag
+	tagRepo := repositories.NewTagRepository(config.DB)
+	tagService := services.NewTagService(tagRepo)
+	tagAPICtrl := controllers.NewTagAPIController(tagService)
+	// Auth
+	authService := services.NewAuthService(repositories.NewUserRepository(config.DB),
+		services.WithSessionTTL(cfg.Auth.SessionTTL))
+	authCtrl := controllers.NewAuthController(authService, cachedTemplates, cfg.Auth.SecureCookie)
+	if hasUsers, err := authService.HasUsers(ctx); err == nil && !hasUsers {
+		log.Println("Belum ada pengguna. Buat dengan `tasktracker useradd <username>` sebelum login.")
+	}
 	// Inisialisasi router dengan static file system
-	router := routes.NewRouter(taskCtrl, welcomesite.StaticFS)
+	router := routes.NewRouter(taskCtrl, taskAPICtrl, tagAPICtrl, authCtrl, welcomesite.StaticFS)
 
-	port := ":8080"
-	log.Printf("Server berjalan di http://localhost%s\n", port)
-	err := http.ListenAndServe(port, router)
+	srv := server.New(cfg.Server, router)
+	ln, err := net.Listen("tcp", cfg.Server.Addr)
 	if err != nil {
 		log.Fatal(err)
 	}
+	log.Printf("Server berjalan di %s\n", ln.Addr())
+	err = server.Serve(ctx, srv, ln, cfg.Server.ShutdownTimeout,
+		server.Drainer{Name: "Koneksi WebSocket", Drain: taskCtrl.CloseWebSockets},
+		server.Drainer{Name: "Pembersih trash", Drain: func(ctx context.Context) error {
+			select {
+			case <-purgerDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}},
+		server.Drainer{Name: "Pembersih file cover", Drain: func(ctx context.Context) error {
+			select {
+			case <-uploadGCDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}},
+		server.Drainer{Name: "Status git", Drain: func(ctx context.Context) error {
+			select {
+			case <-gitRefresherDone:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}},
+		server.Drainer{Name: "Database", Drain: func(context.Context) error {
+			return config.CloseDatabase()
+		}},
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println("Server berhenti")
+}
+
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "migrate":
+		db, err := config.OpenDatabase(cfg.Database.DSN)
+		if err != nil {
+			return err
+		}
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		defer sqlDB.Close()
+		return migrations.RunCommand(migrations.New(db), args[1:], os.Stdout)
+	case "useradd":
+		if err := config.InitDatabase(cfg.Database); err != nil {
+			return err
+		}
+		defer config.CloseDatabase()
+		auth := services.NewAuthService(repositories.NewUserRepository(config.DB))
+		return runUserAdd(auth, args[1:], os.Stdin, os.Stdout)
+	case "gc-uploads":
+		if err := config.InitDatabase(cfg.Database); err != nil {
+			return err
+		}
+		defer config.CloseDatabase()
+		covers, err := newCoverStorage(cfg.Uploads)
+		if err != nil {
+			return err
+		}
+		gc := services.NewUploadGCService(repositories.NewTaskRepository(config.DB), covers)
+		return runGCUploads(gc, cfg.Uploads.GCGrace, args[1:], os.Stdout)
+	default:
+		return fmt.Errorf("perintah %q tidak dikenal", args[0])
+	}
+}
+
+// newCoverStorage membuat storage cover sesuai uploads.storage. Direktori
+// storage lokal dibuat jika belum ada.
+func newCoverStorage(cfg config.UploadsConfig) (storage.Storage, error) {
+	if cfg.Storage == "s3" {
+		return storage.NewS3(cfg.S3, services.CoverURLPrefix)
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	return storage.NewLocal(cfg.Dir, services.CoverURLPrefix), nil
 }
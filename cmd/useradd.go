@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+// runUserAdd menjalankan `tasktracker useradd <username>`. Password dibaca
+// dari terminal tanpa ditampilkan, atau dari baris pertama stdin jika input
+// bukan terminal (misalnya `echo rahasia | tasktracker useradd budi`).
+func runUserAdd(auth services.AuthService, args []string, in *os.File, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("penggunaan: tasktracker useradd <username>")
+	}
+	password, err := readPassword(in, out)
+	if err != nil {
+		return err
+	}
+	user, err := auth.Register(context.Background(), args[0], password)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Pengguna %s dibuat (ID %d)\n", user.Username, user.ID)
+	return nil
+}
+
+func readPassword(in *os.File, out io.Writer) (string, error) {
+	fd := int(in.Fd())
+	if !term.IsTerminal(fd) {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	fmt.Fprint(out, "Password: ")
+	first, err := term.ReadPassword(fd)
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprint(out, "Ulangi password: ")
+	second, err := term.ReadPassword(fd)
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", err
+	}
+	if string(first) != string(second) {
+		return "", errors.New("password tidak sama")
+	}
+	return string(first), nil
+}
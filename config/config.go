@@ -0,0 +1,419 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/storage"
+)
+
+// EnvPrefix adalah awalan semua environment variable konfigurasi.
+const EnvPrefix = "TASKTRACKER_"
+
+// DefaultConfigFile dibaca jika -config dan TASKTRACKER_CONFIG tidak diisi.
+// Berbeda dengan file yang disebut secara eksplisit, file ini boleh tidak ada.
+const DefaultConfigFile = "config.yaml"
+
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Uploads  UploadsConfig  `yaml:"uploads"`
+	Trash    TrashConfig    `yaml:"trash"`
+	Auth     AuthConfig     `yaml:"auth"`
+	Git      GitConfig      `yaml:"git"`
+	// Workflow adalah path file JSON definisi status (lihat workflow.example.json).
+	Workflow string `yaml:"workflow"`
+	// Launchers menambah profil untuk membuka proyek di editor atau
+	// terminal. Profil dengan nama yang sama dengan profil bawaan
+	// menggantikannya.
+	Launchers []models.Launcher `yaml:"launchers"`
+}
+
+type ServerConfig struct {
+	Addr string `yaml:"addr"`
+	// ReadTimeout membatasi waktu membaca satu request, termasuk body upload.
+	ReadTimeout time.Duration `yaml:"read_timeout"`
+	// WriteTimeout membatasi waktu menulis response.
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// IdleTimeout adalah batas koneksi keep-alive menunggu request berikutnya.
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout adalah batas waktu menunggu request dan WebSocket yang
+	// masih berjalan selesai ketika server dimatikan.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+type DatabaseConfig struct {
+	DSN string `yaml:"dsn"`
+	// AutoMigrate menjalankan migrasi yang tertunda saat aplikasi mulai.
+	// Jika false, aplikasi menolak berjalan sampai `migrate up` dijalankan.
+	AutoMigrate bool `yaml:"auto_migrate"`
+}
+
+type UploadsConfig struct {
+	// Storage memilih tempat cover disimpan: "local" (Dir) atau "s3" (S3).
+	Storage string `yaml:"storage"`
+	Dir     string `yaml:"dir"`
+	// S3 dipakai jika Storage "s3".
+	S3 storage.S3Config `yaml:"s3"`
+	// MaxBytes membatasi ukuran file cover yang boleh diunggah.
+	MaxBytes int64 `yaml:"max_bytes"`
+	// CoverWidth adalah lebar maksimum cover setelah di-resize, dalam piksel.
+	// Ini lebar varian full; varian thumb dan medium tidak pernah lebih
+	// lebar.
+	CoverWidth uint `yaml:"cover_width"`
+	// MaxDimension dan MaxPixels membatasi dimensi gambar asli. Keduanya
+	// diperiksa dari header sebelum gambar didecode.
+	MaxDimension int `yaml:"max_dimension"`
+	MaxPixels    int `yaml:"max_pixels"`
+	// GCInterval adalah jarak antar-pembersihan file cover yang tidak
+	// dipakai task mana pun; 0 menonaktifkannya. Perintah gc-uploads tetap
+	// bisa dijalankan manual.
+	GCInterval time.Duration `yaml:"gc_interval"`
+	// GCGrace adalah umur minimum file orphan sebelum dihapus, supaya file
+	// dari upload yang belum selesai tidak ikut terhapus.
+	GCGrace time.Duration `yaml:"gc_grace"`
+}
+
+type TrashConfig struct {
+	// RetentionDays adalah umur task di trash sebelum dihapus permanen;
+	// 0 menonaktifkan purge otomatis.
+	RetentionDays int `yaml:"retention_days"`
+}
+
+type AuthConfig struct {
+	// SessionTTL adalah lama sesi login berlaku.
+	SessionTTL time.Duration `yaml:"session_ttl"`
+	// SecureCookie menandai cookie sesi Secure; aktifkan jika aplikasi
+	// diakses lewat HTTPS.
+	SecureCookie bool `yaml:"secure_cookie"`
+}
+
+type GitConfig struct {
+	// StatusTTL adalah umur maksimum status git proyek di cache.
+	StatusTTL time.Duration `yaml:"status_ttl"`
+	// RefreshInterval adalah jarak antar-pembaruan status git di latar
+	// belakang untuk proyek yang sedang dilihat.
+	RefreshInterval time.Duration `yaml:"refresh_interval"`
+}
+
+// Default mengembalikan konfigurasi yang sama dengan perilaku sebelum
+// konfigurasi bisa diubah.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			ReadTimeout:     time.Minute,
+			WriteTimeout:    time.Minute,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: DatabaseConfig{DSN: "todos.db", AutoMigrate: true},
+		Uploads: UploadsConfig{
+			Storage:      "local",
+			Dir:          "static/uploads/tasks",
+			MaxBytes:     10 << 20,
+			CoverWidth:   800,
+			MaxDimension: 10000,
+			MaxPixels:    40_000_000,
+			GCGrace:      24 * time.Hour,
+		},
+		Trash:    TrashConfig{RetentionDays: 30},
+		Auth:     AuthConfig{SessionTTL: 7 * 24 * time.Hour},
+		Git:      GitConfig{StatusTTL: time.Minute, RefreshInterval: 30 * time.Second},
+		Workflow: "workflow.json",
+	}
+}
+
+// setting menghubungkan satu field Config dengan nama flag dan environment
+// variable-nya.
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"addr", "ADDR", "alamat listen HTTP", func(c *Config, v string) error {
+		c.Server.Addr = v
+		return nil
+	}},
+	{"read-timeout", "READ_TIMEOUT", "batas waktu membaca request, misalnya 1m", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		c.Server.ReadTimeout = d
+		return err
+	}},
+	{"write-timeout", "WRITE_TIMEOUT", "batas waktu menulis response", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		c.Server.WriteTimeout = d
+		return err
+	}},
+	{"idle-timeout", "IDLE_TIMEOUT", "batas waktu koneksi keep-alive menganggur", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		c.Server.IdleTimeout = d
+		return err
+	}},
+	{"shutdown-timeout", "SHUTDOWN_TIMEOUT", "batas waktu menunggu request berjalan saat shutdown", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		c.Server.ShutdownTimeout = d
+		return err
+	}},
+	{"db-dsn", "DB_DSN", "DSN database", func(c *Config, v string) error {
+		c.Database.DSN = v
+		return nil
+	}},
+	{"db-auto-migrate", "DB_AUTO_MIGRATE", "jalankan migrasi yang tertunda saat start", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		c.Database.AutoMigrate = b
+		return err
+	}},
+	{"storage", "STORAGE", "tempat penyimpanan cover: local atau s3", func(c *Config, v string) error {
+		c.Uploads.Storage = v
+		return nil
+	}},
+	{"upload-dir", "UPLOAD_DIR", "direktori penyimpanan cover", func(c *Config, v string) error {
+		c.Uploads.Dir = v
+		return nil
+	}},
+	{"s3-endpoint", "S3_ENDPOINT", "URL endpoint S3, misalnya http://localhost:9000", func(c *Config, v string) error {
+		c.Uploads.S3.Endpoint = v
+		return nil
+	}},
+	{"s3-region", "S3_REGION", "region bucket S3", func(c *Config, v string) error {
+		c.Uploads.S3.Region = v
+		return nil
+	}},
+	{"s3-bucket", "S3_BUCKET", "nama bucket S3 untuk cover", func(c *Config, v string) error {
+		c.Uploads.S3.Bucket = v
+		return nil
+	}},
+	{"s3-access-key-id", "S3_ACCESS_KEY_ID", "access key S3", func(c *Config, v string) error {
+		c.Uploads.S3.AccessKeyID = v
+		return nil
+	}},
+	{"s3-secret-access-key", "S3_SECRET_ACCESS_KEY", "secret key S3; sebaiknya lewat env", func(c *Config, v string) error {
+		c.Uploads.S3.SecretAccessKey = v
+		return nil
+	}},
+	{"s3-public-url", "S3_PUBLIC_URL", "URL publik bucket atau CDN; kosong berarti cover disajikan lewat aplikasi", func(c *Config, v string) error {
+		c.Uploads.S3.PublicURL = v
+		return nil
+	}},
+	{"max-upload-bytes", "MAX_UPLOAD_BYTES", "ukuran maksimum file cover dalam byte", func(c *Config, v string) error {
+		n, err := strconv.ParseInt(v, 10, 64)
+		c.Uploads.MaxBytes = n
+		return err
+	}},
+	{"cover-width", "COVER_WIDTH", "lebar maksimum cover dalam piksel", func(c *Config, v string) error {
+		n, err := strconv.ParseUint(v, 10, 32)
+		c.Uploads.CoverWidth = uint(n)
+		return err
+	}},
+	{"max-image-dimension", "MAX_IMAGE_DIMENSION", "lebar dan tinggi maksimum gambar cover dalam piksel", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Uploads.MaxDimension = n
+		return err
+	}},
+	{"max-image-pixels", "MAX_IMAGE_PIXELS", "jumlah piksel maksimum gambar cover", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Uploads.MaxPixels = n
+		return err
+	}},
+	{"upload-gc-interval", "UPLOAD_GC_INTERVAL", "jarak pembersihan file cover orphan, misalnya 24h (0 = nonaktif)", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		c.Uploads.GCInterval = d
+		return err
+	}},
+	{"upload-gc-grace", "UPLOAD_GC_GRACE", "umur minimum file cover orphan sebelum dihapus", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		c.Uploads.GCGrace = d
+		return err
+	}},
+	{"trash-days", "TRASH_DAYS", "hapus permanen task yang sudah di trash lebih dari N hari (0 = nonaktif)", func(c *Config, v string) error {
+		n, err := strconv.Atoi(v)
+		c.Trash.RetentionDays = n
+		return err
+	}},
+	{"session-ttl", "SESSION_TTL", "lama sesi login berlaku, misalnya 168h", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		c.Auth.SessionTTL = d
+		return err
+	}},
+	{"secure-cookie", "SECURE_COOKIE", "tandai cookie sesi Secure (HTTPS)", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		c.Auth.SecureCookie = b
+		return err
+	}},
+	{"git-status-ttl", "GIT_STATUS_TTL", "umur maksimum status git proyek di cache", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		c.Git.StatusTTL = d
+		return err
+	}},
+	{"git-refresh-interval", "GIT_REFRESH_INTERVAL", "jarak pembaruan status git di latar belakang", func(c *Config, v string) error {
+		d, err := time.ParseDuration(v)
+		c.Git.RefreshInterval = d
+		return err
+	}},
+	{"workflow", "WORKFLOW", "path file JSON workflow status", func(c *Config, v string) error {
+		c.Workflow = v
+		return nil
+	}},
+}
+
+// Load menyusun konfigurasi dengan urutan prioritas: nilai default, file
+// YAML, environment variable TASKTRACKER_*, lalu flag baris perintah.
+// Hasil akhirnya divalidasi. lookupEnv biasanya os.LookupEnv. Argumen
+// setelah flag (misalnya subcommand migrate) ikut dikembalikan.
+func Load(args []string, lookupEnv func(string) (string, bool)) (*Config, []string, error) {
+	fs := flag.NewFlagSet("tasktracker", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path file konfigurasi YAML (default "+DefaultConfigFile+")")
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var flagValues []flagValue
+	for _, s := range settings {
+		fs.Func(s.flag, s.usage+" (env "+EnvPrefix+s.env+")", func(v string) error {
+			flagValues = append(flagValues, flagValue{s, v})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	cfg := Default()
+
+	path, explicit := *configPath, *configPath != ""
+	if !explicit {
+		path, explicit = lookupEnv(EnvPrefix + "CONFIG")
+	}
+	if !explicit {
+		path = DefaultConfigFile
+	}
+	switch err := cfg.loadFile(path); {
+	case err == nil:
+	case !explicit && errors.Is(err, os.ErrNotExist):
+		// File default boleh tidak ada.
+	default:
+		return nil, nil, err
+	}
+
+	for _, s := range settings {
+		v, ok := lookupEnv(EnvPrefix + s.env)
+		if !ok {
+			continue
+		}
+		if err := s.set(cfg, strings.TrimSpace(v)); err != nil {
+			return nil, nil, fmt.Errorf("config: %s%s tidak valid: %w", EnvPrefix, s.env, err)
+		}
+	}
+
+	for _, fv := range flagValues {
+		if err := fv.setting.set(cfg, fv.value); err != nil {
+			return nil, nil, fmt.Errorf("config: flag -%s tidak valid: %w", fv.setting.flag, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: gagal membaca %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate memastikan semua nilai masuk akal sebelum dipakai aplikasi.
+func (c *Config) Validate() error {
+	var problems []string
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		problems = append(problems, fmt.Sprintf("server.addr %q bukan host:port", c.Server.Addr))
+	}
+	for name, d := range map[string]time.Duration{
+		"server.read_timeout":     c.Server.ReadTimeout,
+		"server.write_timeout":    c.Server.WriteTimeout,
+		"server.idle_timeout":     c.Server.IdleTimeout,
+		"server.shutdown_timeout": c.Server.ShutdownTimeout,
+		"auth.session_ttl":        c.Auth.SessionTTL,
+		"git.status_ttl":          c.Git.StatusTTL,
+		"git.refresh_interval":    c.Git.RefreshInterval,
+		"uploads.gc_grace":        c.Uploads.GCGrace,
+	} {
+		if d <= 0 {
+			problems = append(problems, name+" harus lebih dari 0")
+		}
+	}
+	if strings.TrimSpace(c.Database.DSN) == "" {
+		problems = append(problems, "database.dsn tidak boleh kosong")
+	}
+	switch c.Uploads.Storage {
+	case "local":
+		if strings.TrimSpace(c.Uploads.Dir) == "" {
+			problems = append(problems, "uploads.dir tidak boleh kosong")
+		}
+	case "s3":
+		if _, err := storage.NewS3(c.Uploads.S3, ""); err != nil {
+			problems = append(problems, "uploads.s3: "+err.Error())
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("uploads.storage %q harus local atau s3", c.Uploads.Storage))
+	}
+	if c.Uploads.MaxBytes <= 0 {
+		problems = append(problems, "uploads.max_bytes harus lebih dari 0")
+	}
+	if c.Uploads.CoverWidth < 16 || c.Uploads.CoverWidth > 8000 {
+		problems = append(problems, fmt.Sprintf("uploads.cover_width %d harus di antara 16 dan 8000", c.Uploads.CoverWidth))
+	}
+	if c.Uploads.MaxDimension <= 0 {
+		problems = append(problems, "uploads.max_dimension harus lebih dari 0")
+	}
+	if c.Uploads.MaxPixels <= 0 {
+		problems = append(problems, "uploads.max_pixels harus lebih dari 0")
+	}
+	if c.Uploads.GCInterval < 0 {
+		problems = append(problems, "uploads.gc_interval tidak boleh negatif")
+	}
+	if c.Trash.RetentionDays < 0 {
+		problems = append(problems, "trash.retention_days tidak boleh negatif")
+	}
+	if strings.TrimSpace(c.Workflow) == "" {
+		problems = append(problems, "workflow tidak boleh kosong")
+	}
+	seen := make(map[string]bool, len(c.Launchers))
+	for i, l := range c.Launchers {
+		if err := l.Validate(); err != nil {
+			problems = append(problems, fmt.Sprintf("launchers[%d]: %v", i, err))
+			continue
+		}
+		if seen[l.Name] {
+			problems = append(problems, fmt.Sprintf("launchers[%d]: nama %q dipakai lebih dari sekali", i, l.Name))
+		}
+		seen[l.Name] = true
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("config tidak valid: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+)
+
+// LoadWorkflow membaca definisi status dan transisi dari file JSON. Jika file
+// tidak ada, models.DefaultWorkflow yang dipakai.
+func LoadWorkflow(path string) (*models.Workflow, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return models.DefaultWorkflow(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var workflow models.Workflow
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return nil, fmt.Errorf("gagal membaca workflow %s: %w", path, err)
+	}
+	if err := workflow.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &workflow, nil
+}
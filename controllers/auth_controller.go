@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"errors"
+	"html/template"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+// SessionCookieName adalah nama cookie yang menyimpan token sesi.
+const SessionCookieName = "tasktracker_session"
+
+type AuthController struct {
+	service      services.AuthService
+	template     *template.Template
+	secureCookie bool
+}
+
+func NewAuthController(service services.AuthService, tmpl *template.Template, secureCookie bool) *AuthController {
+	return &AuthController{service: service, template: tmpl, secureCookie: secureCookie}
+}
+
+func (c *AuthController) LoginPage(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if _, err := c.currentUser(r); err == nil {
+		http.Redirect(w, r, safeNext(r.URL.Query().Get("next")), http.StatusSeeOther)
+		return
+	}
+	c.renderLogin(w, r, http.StatusOK, "", "", r.URL.Query().Get("next"))
+}
+
+func (c *AuthController) Login(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Request tidak valid", http.StatusBadRequest)
+		return
+	}
+	if !checkLoginCSRF(r) {
+		log.Printf("Token CSRF tidak cocok: %s %s", r.Method, r.URL.Path)
+		http.Error(w, "Token CSRF tidak valid, muat ulang halaman lalu coba lagi", http.StatusForbidden)
+		return
+	}
+	username, next := r.PostFormValue("username"), r.PostFormValue("next")
+	token, session, err := c.service.Login(r.Context(), username, r.PostFormValue("password"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCredentials) {
+			c.renderLogin(w, r, http.StatusUnauthorized, err.Error(), username, next)
+			return
+		}
+		log.Printf("Gagal login: %v", err)
+		http.Error(w, "Gagal login", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Expires:  session.ExpiresAt,
+		HttpOnly: true,
+		Secure:   c.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+	c.setLoginCSRFCookie(w, "", -1)
+	http.Redirect(w, r, safeNext(next), http.StatusSeeOther)
+}
+
+func (c *AuthController) Logout(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		if err := c.service.Logout(r.Context(), cookie.Value); err != nil {
+			log.Printf("Gagal menghapus sesi: %v", err)
+		}
+	}
+	c.clearCookie(w)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// RequireUser menolak request tanpa sesi yang valid, kecuali halaman login
+// dan aset statis. Pengguna yang login disimpan di context request lewat
+// services.WithUser.
+func (c *AuthController) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPublicPath(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		user, err := c.currentUser(r)
+		if err != nil {
+			if !errors.Is(err, services.ErrUnauthenticated) {
+				log.Printf("Gagal memeriksa sesi: %v", err)
+				http.Error(w, "something went wrong", http.StatusInternalServerError)
+				return
+			}
+			c.unauthorized(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(services.WithUser(r.Context(), user)))
+	})
+}
+
+func (c *AuthController) currentUser(r *http.Request) (*models.User, error) {
+	cookie, err := r.Cookie(SessionCookieName)
+	if err != nil {
+		return nil, services.ErrUnauthenticated
+	}
+	return c.service.Authenticate(r.Context(), cookie.Value)
+}
+
+func (c *AuthController) unauthorized(w http.ResponseWriter, r *http.Request) {
+	if _, err := r.Cookie(SessionCookieName); err == nil {
+		c.clearCookie(w)
+	}
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/api/"):
+		writeError(w, http.StatusUnauthorized, "unauthorized", services.ErrUnauthenticated.Error())
+	case r.URL.Path == "/ws":
+		http.Error(w, services.ErrUnauthenticated.Error(), http.StatusUnauthorized)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+	default:
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+	}
+}
+
+func (c *AuthController) clearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (c *AuthController) renderLogin(w http.ResponseWriter, r *http.Request, status int, message, username, next string) {
+	csrf, err := c.loginCSRFToken(w, r)
+	if err != nil {
+		log.Printf("Gagal membuat token CSRF login: %v", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+	hasUsers, err := c.service.HasUsers(r.Context())
+	if err != nil {
+		log.Printf("Gagal menghitung pengguna: %v", err)
+	}
+	data := map[string]any{
+		"Title":     "Login",
+		"Error":     message,
+		"Username":  username,
+		"Next":      next,
+		"NoUsers":   err == nil && !hasUsers,
+		"CSRFToken": csrf,
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	if err := c.template.ExecuteTemplate(w, "login.html", data); err != nil {
+		log.Printf("Error executing template: %v", err)
+	}
+}
+
+// isPublicPath melaporkan path yang boleh diakses tanpa login. Cover task
+// di /static/uploads/ tetap memerlukan login, dan CarController.ServeCover
+// hanya menyajikannya untuk pemilik task.
+func isPublicPath(path string) bool {
+	switch {
+	case path == "/login":
+		return true
+	case strings.HasPrefix(path, "/static/uploads/"):
+		return false
+	default:
+		return strings.HasPrefix(path, "/static/")
+	}
+}
+
+// safeNext hanya menerima path lokal supaya parameter next tidak bisa
+// dipakai untuk mengarahkan pengguna ke situs lain.
+func safeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
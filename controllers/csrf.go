@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"log"
+	"net/http"
+	"strings"
+)
+
+const (
+	// CSRFFieldName adalah nama field form yang membawa token CSRF.
+	CSRFFieldName = "csrf_token"
+	// CSRFHeaderName dipakai klien JavaScript dan API yang tidak mengirim form.
+	CSRFHeaderName = "X-CSRF-Token"
+	// LoginCSRFCookieName menyimpan token CSRF form login. Sebelum login
+	// belum ada sesi untuk menurunkan token, jadi token di cookie ini
+	// dicocokkan dengan field form (double submit).
+	LoginCSRFCookieName = "tasktracker_login_csrf"
+)
+
+type csrfTokenKey struct{}
+
+// RequireCSRF menolak request POST, PUT, PATCH dan DELETE yang tidak membawa
+// token CSRF milik sesinya dengan 403. Middleware ini harus dipasang di
+// dalam RequireUser supaya hanya melihat request yang sudah login; form
+// login diperiksa AuthController.Login dengan token login terpisah karena
+// belum ada sesi.
+//
+// Form multipart di-parse di sini dengan batas maxUploadBytes supaya token
+// di dalamnya bisa dibaca; handler berikutnya memakai hasil parse yang sama.
+func (c *AuthController) RequireCSRF(next http.Handler, maxUploadBytes int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(SessionCookieName)
+		if isPublicPath(r.URL.Path) || err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		expected := c.service.CSRFToken(cookie.Value)
+		r = r.WithContext(context.WithValue(r.Context(), csrfTokenKey{}, expected))
+		if isSafeMethod(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		got := r.Header.Get(CSRFHeaderName)
+		if got == "" {
+			if err := parseCSRFForm(w, r, maxUploadBytes); err != nil {
+				rejectForm(w, r, err)
+				return
+			}
+			got = r.PostFormValue(CSRFFieldName)
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(expected)) != 1 {
+			log.Printf("Token CSRF tidak cocok: %s %s", r.Method, r.URL.Path)
+			if strings.HasPrefix(r.URL.Path, "/api/") {
+				writeError(w, http.StatusForbidden, "csrf_invalid", "token CSRF tidak valid")
+				return
+			}
+			http.Error(w, "Token CSRF tidak valid, muat ulang halaman lalu coba lagi", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loginCSRFToken mengembalikan token CSRF form login dari cookie, atau
+// membuat token baru dan menyimpannya di cookie.
+func (c *AuthController) loginCSRFToken(w http.ResponseWriter, r *http.Request) (string, error) {
+	if cookie, err := r.Cookie(LoginCSRFCookieName); err == nil && cookie.Value != "" {
+		return cookie.Value, nil
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	c.setLoginCSRFCookie(w, token, 0)
+	return token, nil
+}
+
+// checkLoginCSRF memastikan field csrf_token form login sama dengan token di
+// cookie login. Situs lain tidak bisa membaca cookie tersebut, sehingga
+// tidak bisa membuat pengguna login ke akun milik penyerang.
+func checkLoginCSRF(r *http.Request) bool {
+	cookie, err := r.Cookie(LoginCSRFCookieName)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(r.PostFormValue(CSRFFieldName)), []byte(cookie.Value)) == 1
+}
+
+// setLoginCSRFCookie menulis cookie token login; maxAge negatif menghapusnya.
+func (c *AuthController) setLoginCSRFCookie(w http.ResponseWriter, token string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     LoginCSRFCookieName,
+		Value:    token,
+		Path:     "/login",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// csrfToken mengembalikan token CSRF request untuk ditulis ke template.
+func csrfToken(r *http.Request) string {
+	token, _ := r.Context().Value(csrfTokenKey{}).(string)
+	return token
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// parseCSRFForm mem-parsing body form. Body lain, misalnya JSON, dibiarkan
+// utuh untuk handler dan harus membawa token lewat header.
+func parseCSRFForm(w http.ResponseWriter, r *http.Request, maxUploadBytes int64) error {
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		return parseUploadForm(w, r, maxUploadBytes)
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		return r.ParseForm()
+	}
+	return nil
+}
+
+func rejectForm(w http.ResponseWriter, r *http.Request, err error) {
+	api := strings.HasPrefix(r.URL.Path, "/api/")
+	switch {
+	case isTooLarge(err) && api:
+		writeError(w, http.StatusRequestEntityTooLarge, "payload_too_large", "file cover terlalu besar")
+	case isTooLarge(err):
+		http.Error(w, "File cover terlalu besar", http.StatusRequestEntityTooLarge)
+	case api:
+		writeError(w, http.StatusBadRequest, "invalid_request", "form tidak valid")
+	default:
+		log.Printf("Tidak dapat mem-parsing form: %v", err)
+		http.Error(w, "Request tidak valid", http.StatusBadRequest)
+	}
+}
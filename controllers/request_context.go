@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+// requestContext menyiapkan context untuk pemanggilan service, termasuk
+// pelaku perubahan yang dicatat di riwayat task. Pengguna yang login sudah
+// disimpan di context oleh AuthController.RequireUser.
+func requestContext(r *http.Request) context.Context {
+	return services.WithActor(r.Context(), actorFromRequest(r))
+}
+
+// actorFromRequest memakai username pengguna yang login, atau alamat IP
+// klien jika request tidak melewati login.
+func actorFromRequest(r *http.Request) string {
+	if user, ok := services.UserFromContext(r.Context()); ok {
+		return user.Username
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// currentUser mengembalikan pengguna yang login untuk ditampilkan di
+// template, atau nil.
+func currentUser(r *http.Request) *models.User {
+	user, _ := services.UserFromContext(r.Context())
+	return user
+}
@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+type tagRequest struct {
+	Name string `json:"name"`
+}
+
+type mergeTagRequest struct {
+	Into uint `json:"into"`
+}
+
+type TagAPIController struct {
+	service services.TagService
+}
+
+func NewTagAPIController(service services.TagService) *TagAPIController {
+	return &TagAPIController{service: service}
+}
+
+func (c *TagAPIController) ListTags(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tags, err := c.service.GetAllTags(requestContext(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if tags == nil {
+		tags = []models.Tag{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": tags})
+}
+
+func (c *TagAPIController) CreateTag(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var req tagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	tag, err := c.service.CreateTag(requestContext(r), req.Name)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, map[string]any{"data": tag})
+}
+
+// RenameTag mengganti nama tag milik pengguna; semua task yang memakainya
+// ikut berubah.
+func (c *TagAPIController) RenameTag(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	var req tagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	tag, err := c.service.RenameTag(requestContext(r), id, req.Name)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": tag})
+}
+
+func (c *TagAPIController) DeleteTag(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteTag(requestContext(r), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// MergeTags menggabungkan tag :id ke tag {"into": id} lalu menghapus tag :id.
+func (c *TagAPIController) MergeTags(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	var req mergeTagRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	tag, err := c.service.MergeTags(requestContext(r), id, req.Into)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": tag})
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "body JSON tidak valid: "+err.Error())
+		return false
+	}
+	return true
+}
@@ -0,0 +1,421 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/utils"
+	"github.com/nabilulilalbab/welcomesite/validation"
+)
+
+// apiError adalah bentuk objek error yang dikembalikan oleh semua endpoint /api.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Fields berisi pesan per field untuk error validation_failed.
+	Fields validation.Errors `json:"fields,omitempty"`
+}
+
+type apiErrorResponse struct {
+	Error apiError `json:"error"`
+}
+
+// taskRequest adalah payload untuk membuat atau mengubah task lewat API.
+type taskRequest struct {
+	Judul       string   `json:"judul"`
+	Tipe        string   `json:"tipe"`
+	Status      string   `json:"status"`
+	PathProject *string  `json:"path_project"`
+	LinkWebsite *string  `json:"link_website"`
+	Tags        []string `json:"tags"`
+	Catatan     string   `json:"catatan"`
+	Launcher    string   `json:"launcher"`
+}
+
+func (req taskRequest) toTask() *models.Task {
+	return &models.Task{
+		Judul:       req.Judul,
+		Tipe:        req.Tipe,
+		Status:      req.Status,
+		PathProject: req.PathProject,
+		LinkWebsite: req.LinkWebsite,
+		Tags:        tagsInput(req.Tags),
+		Catatan:     req.Catatan,
+		Launcher:    req.Launcher,
+	}
+}
+
+// tagsInput menjaga perbedaan nil (tag tidak diubah) dan slice kosong.
+func tagsInput(names []string) []models.Tag {
+	if names == nil {
+		return nil
+	}
+	return models.TagsFromNames(names)
+}
+
+type TaskAPIController struct {
+	service   services.TaskService
+	gitStatus services.GitStatusService
+}
+
+// TaskAPIControllerOption mengatur dependensi opsional TaskAPIController.
+type TaskAPIControllerOption func(*TaskAPIController)
+
+// WithGitStatus memakai GitStatusService yang cache-nya diperbarui
+// services.RunGitStatusRefresher. Tanpa opsi ini dipakai service baru
+// tanpa pembaruan latar belakang.
+func WithGitStatus(gitStatus services.GitStatusService) TaskAPIControllerOption {
+	return func(c *TaskAPIController) {
+		c.gitStatus = gitStatus
+	}
+}
+
+func NewTaskAPIController(service services.TaskService, opts ...TaskAPIControllerOption) *TaskAPIController {
+	c := &TaskAPIController{service: service}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.gitStatus == nil {
+		c.gitStatus = services.NewGitStatusService(service)
+	}
+	return c
+}
+
+func (c *TaskAPIController) ListTasks(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	query, err := parseTaskQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_query", err.Error())
+		return
+	}
+	page, err := c.service.ListTasks(requestContext(r), query)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if page.Tasks == nil {
+		page.Tasks = []models.Task{}
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (c *TaskAPIController) GetTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	task, err := c.service.GetTaskByID(requestContext(r), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": task})
+}
+
+func (c *TaskAPIController) CreateTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	req, coverFile, ok := decodeTaskRequest(w, r, c.service.Uploads().MaxBytes)
+	if !ok {
+		return
+	}
+	task := req.toTask()
+	if errs := validation.Task(task, false); len(errs) > 0 {
+		writeValidationError(w, errs)
+		return
+	}
+	created, err := c.service.CreateTask(requestContext(r), task, coverFile)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/v1/tasks/"+strconv.FormatUint(uint64(created.ID), 10))
+	writeJSON(w, http.StatusCreated, map[string]any{"data": created})
+}
+
+// UpdateTask mengganti seluruh task. Field opsional yang tidak dikirim atau
+// kosong dikosongkan; status yang tidak dikirim dan cover tidak diubah.
+// Gunakan PATCH untuk mengubah sebagian field.
+func (c *TaskAPIController) UpdateTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	req, coverFile, ok := decodeTaskRequest(w, r, c.service.Uploads().MaxBytes)
+	if !ok {
+		return
+	}
+	task := req.toTask()
+	if errs := validation.Task(task, false); len(errs) > 0 {
+		writeValidationError(w, errs)
+		return
+	}
+	updated, err := c.service.PatchTask(requestContext(r), id, models.ReplacePatchFromTask(task), coverFile)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": updated})
+}
+
+// PatchTask mengubah sebagian task. Field yang tidak ada di body tidak
+// diubah; null atau string kosong mengosongkan field, kecuali judul, tipe
+// dan status yang wajib terisi.
+func (c *TaskAPIController) PatchTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	patch, coverFile, ok := decodeTaskPatch(w, r, c.service.Uploads().MaxBytes)
+	if !ok {
+		return
+	}
+	if errs := validation.TaskPatch(patch); len(errs) > 0 {
+		writeValidationError(w, errs)
+		return
+	}
+	updated, err := c.service.PatchTask(requestContext(r), id, patch, coverFile)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": updated})
+}
+
+func (c *TaskAPIController) DeleteTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	if err := c.service.DeleteTask(requestContext(r), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RemoveCover mengosongkan cover task dan mengembalikan task terbaru.
+func (c *TaskAPIController) RemoveCover(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	task, err := c.service.RemoveCover(requestContext(r), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": task})
+}
+
+// ListTrash mengembalikan task yang sudah dihapus tetapi belum di-purge.
+func (c *TaskAPIController) ListTrash(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tasks, err := c.service.ListTrash(requestContext(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if tasks == nil {
+		tasks = []models.Task{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": tasks})
+}
+
+func (c *TaskAPIController) RestoreTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	task, err := c.service.RestoreTask(requestContext(r), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": task})
+}
+
+// PurgeTask menghapus permanen task yang ada di trash.
+func (c *TaskAPIController) PurgeTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	if err := c.service.PurgeTask(requestContext(r), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTaskEvents mengembalikan riwayat perubahan task, yang terbaru lebih dulu.
+func (c *TaskAPIController) ListTaskEvents(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	events, err := c.service.GetTaskEvents(requestContext(r), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	if events == nil {
+		events = []models.TaskEvent{}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": events})
+}
+
+// GetGitStatus mengembalikan status git PathProject task.
+func (c *TaskAPIController) GetGitStatus(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, ok := parseIDParam(w, ps)
+	if !ok {
+		return
+	}
+	status, err := c.gitStatus.Status(requestContext(r), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": status})
+}
+
+// GetWorkflow mengembalikan status dan transisi yang berlaku.
+func (c *TaskAPIController) GetWorkflow(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	writeJSON(w, http.StatusOK, map[string]any{"data": c.service.Workflow()})
+}
+
+// decodeTaskRequest membaca body JSON, atau multipart form jika klien ingin
+// sekalian mengunggah cover.
+func decodeTaskRequest(w http.ResponseWriter, r *http.Request, maxUploadBytes int64) (taskRequest, *multipart.FileHeader, bool) {
+	var req taskRequest
+	if isMultipart(r) {
+		fileHeader, ok := parseTaskMultipart(w, r, maxUploadBytes)
+		if !ok {
+			return req, nil, false
+		}
+		req = taskRequest{
+			Judul:    r.FormValue("judul"),
+			Tipe:     r.FormValue("tipe"),
+			Status:   r.FormValue("status"),
+			Catatan:  r.FormValue("catatan"),
+			Launcher: r.FormValue("launcher"),
+		}
+		if v := r.FormValue("tags"); v != "" {
+			req.Tags = models.ParseTagNames(v)
+		}
+		if v := r.FormValue("path_project"); v != "" {
+			req.PathProject = &v
+		}
+		if v := r.FormValue("link_website"); v != "" {
+			req.LinkWebsite = &v
+		}
+		return req, fileHeader, true
+	}
+
+	return req, nil, decodeJSON(w, r, &req)
+}
+
+// decodeTaskPatch membaca TaskPatch dari JSON atau multipart form; lihat
+// formPatch untuk arti field form.
+func decodeTaskPatch(w http.ResponseWriter, r *http.Request, maxUploadBytes int64) (*models.TaskPatch, *multipart.FileHeader, bool) {
+	if isMultipart(r) {
+		fileHeader, ok := parseTaskMultipart(w, r, maxUploadBytes)
+		if !ok {
+			return nil, nil, false
+		}
+		return formPatch(r), fileHeader, true
+	}
+	patch := &models.TaskPatch{}
+	return patch, nil, decodeJSON(w, r, patch)
+}
+
+func isMultipart(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data")
+}
+
+// parseTaskMultipart mem-parsing form dan mengembalikan file cover jika ada.
+func parseTaskMultipart(w http.ResponseWriter, r *http.Request, maxUploadBytes int64) (*multipart.FileHeader, bool) {
+	if err := parseUploadForm(w, r, maxUploadBytes); err != nil {
+		if isTooLarge(err) {
+			writeError(w, http.StatusRequestEntityTooLarge, "payload_too_large", "file cover terlalu besar")
+			return nil, false
+		}
+		writeError(w, http.StatusBadRequest, "invalid_request", "multipart form tidak valid")
+		return nil, false
+	}
+	_, fileHeader, err := r.FormFile("cover")
+	if err != nil && err != http.ErrMissingFile {
+		writeError(w, http.StatusBadRequest, "invalid_request", "gagal memproses file cover")
+		return nil, false
+	}
+	return fileHeader, true
+}
+
+func parseIDParam(w http.ResponseWriter, ps httprouter.Params) (uint, bool) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_id", "ID tidak valid")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// writeServiceError memetakan error dari service ke status HTTP yang sesuai.
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTaskNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "task tidak ditemukan")
+	case errors.Is(err, services.ErrInvalidQuery):
+		writeError(w, http.StatusBadRequest, "invalid_query", err.Error())
+	case errors.Is(err, services.ErrIllegalTransition):
+		writeError(w, http.StatusConflict, "illegal_transition", err.Error())
+	case errors.Is(err, services.ErrUnknownStatus):
+		writeError(w, http.StatusUnprocessableEntity, "unknown_status", err.Error())
+	case errors.Is(err, services.ErrUnknownLauncher):
+		writeError(w, http.StatusUnprocessableEntity, "unknown_launcher", err.Error())
+	case errors.Is(err, services.ErrTagNotFound):
+		writeError(w, http.StatusNotFound, "not_found", "tag tidak ditemukan")
+	case errors.Is(err, services.ErrTagExists):
+		writeError(w, http.StatusConflict, "tag_exists", err.Error())
+	case errors.Is(err, services.ErrCoverTooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, "payload_too_large", err.Error())
+	case errors.Is(err, utils.ErrUnsupportedImage), errors.Is(err, utils.ErrImageTooLarge):
+		writeValidationError(w, validation.Errors{"cover": err.Error()})
+	case errors.Is(err, services.ErrNoProjectPath):
+		writeError(w, http.StatusUnprocessableEntity, "no_project_path", err.Error())
+	case errors.Is(err, services.ErrInvalidProjectPath):
+		writeError(w, http.StatusUnprocessableEntity, "invalid_project_path", err.Error())
+	case errors.Is(err, utils.ErrGitUnavailable):
+		writeError(w, http.StatusServiceUnavailable, "git_unavailable", err.Error())
+	case errors.Is(err, services.ErrInvalidTag):
+		writeError(w, http.StatusUnprocessableEntity, "validation_failed", err.Error())
+	default:
+		log.Printf("API: error dari service: %v", err)
+		writeError(w, http.StatusInternalServerError, "internal_error", "terjadi kesalahan pada server")
+	}
+}
+
+func writeValidationError(w http.ResponseWriter, errs validation.Errors) {
+	writeJSON(w, http.StatusUnprocessableEntity, apiErrorResponse{Error: apiError{
+		Code:    "validation_failed",
+		Message: errs.Error(),
+		Fields:  errs,
+	}})
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, apiErrorResponse{Error: apiError{Code: code, Message: message}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("API: gagal menulis response JSON: %v", err)
+	}
+}
@@ -1,57 +1,201 @@
 package controllers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"html/template"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/julienschmidt/httprouter"
 
 	"github.com/nabilulilalbab/welcomesite/models"
 	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/storage"
 	"github.com/nabilulilalbab/welcomesite/utils"
+	"github.com/nabilulilalbab/welcomesite/validation"
+	"github.com/nabilulilalbab/welcomesite/view"
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true
-	},
-}
+// upgrader memakai pemeriksaan origin bawaan gorilla: handshake dari
+// browser hanya diterima jika header Origin sama dengan host server, sehingga
+// halaman situs lain tidak bisa membuka WebSocket dengan cookie pengguna.
+var upgrader = websocket.Upgrader{}
 
 type CarController struct {
-	service  services.TaskService
-	template *template.Template
+	service       services.TaskService
+	template      *template.Template
+	hub           *Hub
+	launchProject func(launcher, dir string) error
+	wsPongWait    time.Duration
+}
+
+// TaskControllerOption mengatur dependensi opsional CarController.
+type TaskControllerOption func(*CarController)
+
+// WithWebSocketPongWait mengganti DefaultWebSocketPongWait. Ping dikirim
+// setiap 9/10 dari durasi ini.
+func WithWebSocketPongWait(d time.Duration) TaskControllerOption {
+	return func(c *CarController) {
+		c.wsPongWait = d
+	}
+}
+
+// WithHub memakai hub yang sama dengan yang dipasang di TaskService lewat
+// services.WithNotifier, supaya perubahan task sampai ke klien WebSocket.
+func WithHub(hub *Hub) TaskControllerOption {
+	return func(c *CarController) {
+		c.hub = hub
+	}
+}
+
+// WithProjectLauncher mengganti cara menjalankan profil launcher, yang
+// secara bawaan memakai Launchers().Open dari TaskService, misalnya di test.
+func WithProjectLauncher(launch func(launcher, dir string) error) TaskControllerOption {
+	return func(c *CarController) {
+		c.launchProject = launch
+	}
 }
 
-func NewTaskController(service services.TaskService, tmpl *template.Template) *CarController {
-	return &CarController{service: service, template: tmpl}
+func NewTaskController(service services.TaskService, tmpl *template.Template, opts ...TaskControllerOption) *CarController {
+	c := &CarController{
+		service:       service,
+		template:      tmpl,
+		launchProject: service.Launchers().Open,
+		wsPongWait:    DefaultWebSocketPongWait,
+		hub:           NewHub(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *CarController) ListTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	tasks, err := c.service.GetAllTasks()
+	c.renderIndex(w, r, http.StatusOK, nil)
+}
+
+// renderIndex menampilkan daftar task sesuai query URL. form terisi jika
+// halaman dirender ulang karena isi modal tambah/edit ditolak.
+func (c *CarController) renderIndex(w http.ResponseWriter, r *http.Request, status int, form *view.TaskForm) {
+	values := r.URL.Query()
+	query, err := parseTaskQuery(values)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	page, err := c.service.ListTasks(requestContext(r), query)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidQuery) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Gagal mengambil daftar task: %v", err)
 		http.Error(w, "gagal ambil task nih", http.StatusInternalServerError)
 		return
 	}
 
 	data := map[string]any{
-		"Title":     "Home Task",
-		"Tasks":     tasks,
-		"OS":        utils.GetOS(),
-		"Terminals": utils.GetAvailableTerminals(),
+		"Title":       "Home Task",
+		"Tasks":       page.Tasks,
+		"Total":       page.Total,
+		"Query":       values,
+		"Filtered":    isFiltered(query),
+		"Workflow":    c.service.Workflow(),
+		"NextPageURL": nextPageURL("/", values, page.NextCursor),
+		"OS":          utils.GetOS(),
+		"Launchers":   c.service.Launchers().Available(),
+		"User":        currentUser(r),
+		"CSRFToken":   csrfToken(r),
+		"Form":        form,
 	}
 
-	if err := c.template.ExecuteTemplate(w, "indextask.html", data); err != nil {
+	var buf bytes.Buffer
+	if err := c.template.ExecuteTemplate(&buf, "indextask.html", data); err != nil {
 		log.Printf("Error executing template: %v", err)
 		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
 	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+// taskFormFields adalah input modal tambah/edit yang dikembalikan ke
+// halaman ketika form ditolak. File cover tidak bisa dikembalikan.
+var taskFormFields = []string{"judul", "tipe", "status", "path_project", "link_website", "tags", "catatan", "launcher"}
+
+// rejectTaskForm merender ulang halaman dengan modal yang berisi input
+// pengguna dan pesan error per field.
+func (c *CarController) rejectTaskForm(w http.ResponseWriter, r *http.Request, status int, form *view.TaskForm) {
+	form.Values = make(map[string]string, len(taskFormFields))
+	for _, field := range taskFormFields {
+		form.Values[field] = r.FormValue(field)
+	}
+	c.renderIndex(w, r, status, form)
+}
+
+// formStatus adalah status HTTP form yang ditolak. Cover yang melebihi
+// batas ukuran tetap 413 meskipun pesannya tampil di form.
+func formStatus(err error) int {
+	if errors.Is(err, services.ErrCoverTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusUnprocessableEntity
+}
+
+// formPatch membuat TaskPatch dari form: input yang ada di form diubah atau
+// dikosongkan, input yang tidak dikirim tidak diubah.
+func formPatch(r *http.Request) *models.TaskPatch {
+	patch := &models.TaskPatch{}
+	fields := map[string]*models.PatchField[string]{
+		"judul":        &patch.Judul,
+		"status":       &patch.Status,
+		"tipe":         &patch.Tipe,
+		"path_project": &patch.PathProject,
+		"link_website": &patch.LinkWebsite,
+		"catatan":      &patch.Catatan,
+		"launcher":     &patch.Launcher,
+	}
+	for name, field := range fields {
+		if _, ok := r.PostForm[name]; ok {
+			*field = models.SetField(r.PostFormValue(name))
+		}
+	}
+	if _, ok := r.PostForm["tags"]; ok {
+		patch.Tags = models.SetField(models.ParseTagNames(r.PostFormValue("tags")))
+	}
+	return patch
+}
+
+// fieldErrors mengubah error service yang berasal dari satu field form
+// menjadi validation.Errors. Hasilnya nil untuk error lain.
+func fieldErrors(err error) validation.Errors {
+	switch {
+	case errors.Is(err, services.ErrUnknownStatus), errors.Is(err, services.ErrIllegalTransition):
+		return validation.Errors{"status": err.Error()}
+	case errors.Is(err, services.ErrUnknownLauncher):
+		return validation.Errors{"launcher": err.Error()}
+	case errors.Is(err, services.ErrInvalidTag):
+		return validation.Errors{"tags": err.Error()}
+	case errors.Is(err, services.ErrCoverTooLarge), errors.Is(err, utils.ErrUnsupportedImage), errors.Is(err, utils.ErrImageTooLarge):
+		return validation.Errors{"cover": err.Error()}
+	}
+	return nil
 }
 
 func (c *CarController) ProcessAddTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
+	if err := parseUploadForm(w, r, c.service.Uploads().MaxBytes); err != nil {
+		if isTooLarge(err) {
+			http.Error(w, "File cover terlalu besar", http.StatusRequestEntityTooLarge)
+			return
+		}
 		log.Printf("Tidak dapat mem-parsing multipart form: %v", err)
 		http.Error(w, "Request tidak valid", http.StatusBadRequest)
 		return
@@ -66,11 +210,11 @@ func (c *CarController) ProcessAddTask(w http.ResponseWriter, r *http.Request, p
 		defer file.Close()
 	}
 	task := &models.Task{
-		Judul:   r.FormValue("judul"),
-		Tipe:    r.FormValue("tipe"),
-		Tags:    r.FormValue("tags"),
-		Catatan: r.FormValue("catatan"),
-		Status:  "todo",
+		Judul:    r.FormValue("judul"),
+		Tipe:     r.FormValue("tipe"),
+		Tags:     models.TagsFromNames(models.ParseTagNames(r.FormValue("tags"))),
+		Catatan:  r.FormValue("catatan"),
+		Launcher: r.FormValue("launcher"),
 	}
 	pathProjectVal := r.FormValue("path_project")
 	if pathProjectVal != "" {
@@ -80,8 +224,16 @@ func (c *CarController) ProcessAddTask(w http.ResponseWriter, r *http.Request, p
 	if linkWebsiteVal != "" {
 		task.LinkWebsite = &linkWebsiteVal
 	}
-	_, err = c.service.CreateTask(task, fileHeader)
+	if errs := validation.Task(task, false); len(errs) > 0 {
+		c.rejectTaskForm(w, r, http.StatusUnprocessableEntity, &view.TaskForm{Modal: "add", Errors: errs})
+		return
+	}
+	_, err = c.service.CreateTask(requestContext(r), task, fileHeader)
 	if err != nil {
+		if errs := fieldErrors(err); errs != nil {
+			c.rejectTaskForm(w, r, formStatus(err), &view.TaskForm{Modal: "add", Errors: errs})
+			return
+		}
 		log.Printf("Error saat memanggil service CreateTask: %v", err)
 		http.Error(w, "Gagal menyimpan data task", http.StatusInternalServerError)
 		return
@@ -96,29 +248,113 @@ func (c *CarController) HandleWebSocket(w http.ResponseWriter, r *http.Request,
 		return
 	}
 	defer conn.Close()
+	var userID uint
+	if user := currentUser(r); user != nil {
+		userID = user.ID
+	}
+	client := newWSClient(conn, userID)
+	if !c.hub.add(client) {
+		conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.CloseGoingAway, "server dimatikan"), time.Now().Add(time.Second))
+		return
+	}
+	defer c.hub.remove(client)
+
+	conn.SetReadLimit(wsMaxMessageBytes)
+	extend := readDeadline(conn, c.wsPongWait)
+	go client.writePump(c.wsPongWait * 9 / 10)
+	defer client.close()
 
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
-			log.Println("Gagal membaca pesan WebSocket:", err)
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Println("Gagal membaca pesan WebSocket:", err)
+			}
 			break
 		}
-
-		var msg map[string]string
-		if err := json.Unmarshal(message, &msg); err != nil {
-			log.Println("Gagal unmarshal JSON:", err)
-			continue
+		extend()
+		if !client.queue(c.handleWSMessage(r, message)) {
+			break
 		}
+	}
+}
+
+// handleWSMessage menjalankan satu perintah dari klien dan menyusun
+// balasannya. Pesan yang tidak valid juga dibalas dengan error.
+func (c *CarController) handleWSMessage(r *http.Request, message []byte) wsResponse {
+	var req wsRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		return wsFailure("", "invalid_json", "pesan bukan JSON yang valid")
+	}
+	switch req.Type {
+	case wsTypeOpenTerminal:
+		return c.openTerminal(r, req)
+	default:
+		return wsFailure(req.ID, "unknown_type", fmt.Sprintf("tipe pesan %q tidak dikenal", req.Type))
+	}
+}
+
+// openTerminal membuka PathProject task dengan profil launcher. Klien hanya
+// menyebut ID task dan nama profil; path diambil dari database. Jika
+// launcher tidak disebut, dipakai launcher yang tersimpan di task.
+func (c *CarController) openTerminal(r *http.Request, req wsRequest) wsResponse {
+	var payload openTerminalPayload
+	dec := json.NewDecoder(bytes.NewReader(req.Payload))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&payload); err != nil {
+		return wsFailure(req.ID, "invalid_payload", "payload open_terminal tidak valid")
+	}
+
+	ctx := requestContext(r)
+	dir, err := c.service.ProjectDir(ctx, payload.TaskID)
+	switch {
+	case errors.Is(err, services.ErrTaskNotFound):
+		return wsFailure(req.ID, "not_found", err.Error())
+	case errors.Is(err, services.ErrNoProjectPath):
+		return wsFailure(req.ID, "no_project_path", err.Error())
+	case errors.Is(err, services.ErrInvalidProjectPath):
+		return wsFailure(req.ID, "invalid_project_path", err.Error())
+	case err != nil:
+		log.Printf("Gagal mengambil path proyek task ID %d: %v", payload.TaskID, err)
+		return wsFailure(req.ID, "internal_error", "terjadi kesalahan pada server")
+	}
 
-		terminalCmd := msg["terminal"]
-		path := msg["path"]
+	launcher := payload.launcher()
+	if launcher == "" {
+		task, err := c.service.GetTaskByID(ctx, payload.TaskID)
+		if err != nil {
+			log.Printf("Gagal mengambil task ID %d: %v", payload.TaskID, err)
+			return wsFailure(req.ID, "internal_error", "terjadi kesalahan pada server")
+		}
+		launcher = task.Launcher
+	}
+	if launcher == "" {
+		return wsFailure(req.ID, "no_launcher", "pilih launcher untuk membuka proyek")
+	}
 
-		if err := utils.OpenTerminal(terminalCmd, path); err != nil {
-			log.Printf("Gagal membuka terminal: %v", err)
-		} else {
-			log.Printf("Berhasil membuka %s di %s", path, terminalCmd)
+	if err := c.launchProject(launcher, dir); err != nil {
+		if errors.Is(err, utils.ErrUnknownLauncher) {
+			return wsFailure(req.ID, "unknown_launcher", err.Error())
 		}
+		log.Printf("Gagal menjalankan launcher %s: %v", launcher, err)
+		return wsFailure(req.ID, "launch_failed", "gagal membuka proyek")
 	}
+	log.Printf("Berhasil membuka %s dengan %s", dir, launcher)
+	return wsAck(req.ID, map[string]any{"task_id": payload.TaskID, "launcher": launcher})
+}
+
+// MaxUploadBytes adalah batas ukuran cover, dipakai middleware yang perlu
+// mem-parsing form upload sebelum sampai ke handler.
+func (c *CarController) MaxUploadBytes() int64 {
+	return c.service.Uploads().MaxBytes
+}
+
+// CloseWebSockets menolak koneksi WebSocket baru, meminta klien yang masih
+// terhubung menutup koneksinya, lalu menunggu sampai semuanya selesai atau
+// ctx habis.
+func (c *CarController) CloseWebSockets(ctx context.Context) error {
+	return c.hub.closeAll(ctx)
 }
 
 func (c *CarController) ProcessUpdateTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
@@ -129,7 +365,11 @@ func (c *CarController) ProcessUpdateTask(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
+	if err := parseUploadForm(w, r, c.service.Uploads().MaxBytes); err != nil {
+		if isTooLarge(err) {
+			http.Error(w, "File cover terlalu besar", http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "Request tidak valid", http.StatusBadRequest)
 		return
 	}
@@ -143,25 +383,31 @@ func (c *CarController) ProcessUpdateTask(w http.ResponseWriter, r *http.Request
 		defer file.Close()
 	}
 
-	taskInput := &models.Task{
-		Judul:   r.FormValue("judul"),
-		Tipe:    r.FormValue("tipe"),
-		Tags:    r.FormValue("tags"),
-		Catatan: r.FormValue("catatan"),
-		Status:  r.FormValue("status"),
-	}
-	pathProjectVal := r.FormValue("path_project")
-	if pathProjectVal != "" {
-		taskInput.PathProject = &pathProjectVal
+	// Input yang dikirim tetapi kosong mengosongkan field, sehingga catatan,
+	// tag dan path bisa dihapus dari modal edit.
+	patch := formPatch(r)
+	errs := validation.TaskPatch(patch)
+	if len(errs) == 0 {
+		_, err = c.service.PatchTask(requestContext(r), uint(id), patch, fileHeader)
+		errs = fieldErrors(err)
 	}
-	linkWebsiteVal := r.FormValue("link_website")
-	if linkWebsiteVal != "" {
-		taskInput.LinkWebsite = &linkWebsiteVal
+	if len(errs) > 0 {
+		existing, findErr := c.service.GetTaskByID(requestContext(r), uint(id))
+		if findErr != nil {
+			http.Error(w, "Task tidak ditemukan", http.StatusNotFound)
+			return
+		}
+		c.rejectTaskForm(w, r, formStatus(err), &view.TaskForm{Modal: "edit", TaskID: existing.ID, Status: existing.Status, Errors: errs})
+		return
 	}
-	_, err = c.service.UpdateTask(uint(id), taskInput, fileHeader)
 	if err != nil {
-		log.Printf("Error saat memanggil service UpdateTask: %v", err)
-		http.Error(w, "Gagal mengupdate data task", http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, services.ErrTaskNotFound):
+			http.Error(w, "Task tidak ditemukan", http.StatusNotFound)
+		default:
+			log.Printf("Error saat memanggil service UpdateTask: %v", err)
+			http.Error(w, "Gagal mengupdate data task", http.StatusInternalServerError)
+		}
 		return
 	}
 
@@ -176,7 +422,11 @@ func (c *CarController) DeleteTask(w http.ResponseWriter, r *http.Request, ps ht
 		return
 	}
 
-	err = c.service.DeleteTask(uint(id))
+	err = c.service.DeleteTask(requestContext(r), uint(id))
+	if errors.Is(err, services.ErrTaskNotFound) {
+		http.Error(w, "Task tidak ditemukan", http.StatusNotFound)
+		return
+	}
 	if err != nil {
 		log.Printf("Gagal menghapus task ID %d: %v", id, err)
 		http.Error(w, "Gagal menghapus task", http.StatusInternalServerError)
@@ -185,3 +435,124 @@ func (c *CarController) DeleteTask(w http.ResponseWriter, r *http.Request, ps ht
 
 	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
+
+// RemoveCover dipanggil tombol "Hapus cover" di modal edit.
+func (c *CarController) RemoveCover(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "ID tidak valid", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := c.service.RemoveCover(requestContext(r), uint(id)); err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			http.Error(w, "Task tidak ditemukan", http.StatusNotFound)
+			return
+		}
+		log.Printf("Gagal menghapus cover task ID %d: %v", id, err)
+		http.Error(w, "Gagal menghapus cover", http.StatusInternalServerError)
+		return
+	}
+
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// ServeCover menyajikan file cover dari storage, tetapi hanya jika file itu
+// cover task milik pengguna yang login. File lain dijawab 404 supaya
+// keberadaannya tidak terungkap.
+func (c *CarController) ServeCover(w http.ResponseWriter, r *http.Request, key string) {
+	owned, err := c.service.OwnsCover(requestContext(r), key)
+	if err != nil {
+		log.Printf("Gagal memeriksa pemilik cover %s: %v", key, err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+		return
+	}
+	if !owned {
+		http.NotFound(w, r)
+		return
+	}
+	storage.Serve(w, r, c.service.Covers(), key)
+}
+
+// TaskCard merender satu kartu task. Halaman memakainya untuk mengganti
+// kartu yang berubah tanpa memuat ulang seluruh daftar.
+func (c *CarController) TaskCard(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "ID tidak valid", http.StatusBadRequest)
+		return
+	}
+	task, err := c.service.GetTaskByID(requestContext(r), uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			http.Error(w, "Task tidak ditemukan", http.StatusNotFound)
+			return
+		}
+		log.Printf("Gagal mengambil task ID %d: %v", id, err)
+		http.Error(w, "Gagal mengambil task", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	card := view.TaskCard{Task: *task, Workflow: c.service.Workflow()}
+	if err := c.template.ExecuteTemplate(w, "task_card", card); err != nil {
+		log.Printf("Error executing template: %v", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+	}
+}
+
+// ListTrash menampilkan task yang sudah dihapus beserta tombol restore dan
+// hapus permanen.
+func (c *CarController) ListTrash(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	tasks, err := c.service.ListTrash(requestContext(r))
+	if err != nil {
+		log.Printf("Gagal mengambil isi trash: %v", err)
+		http.Error(w, "Gagal mengambil isi trash", http.StatusInternalServerError)
+		return
+	}
+	data := map[string]any{
+		"Title":     "Trash",
+		"Tasks":     tasks,
+		"Workflow":  c.service.Workflow(),
+		"CSRFToken": csrfToken(r),
+	}
+	if err := c.template.ExecuteTemplate(w, "trash.html", data); err != nil {
+		log.Printf("Error executing template: %v", err)
+		http.Error(w, "something went wrong", http.StatusInternalServerError)
+	}
+}
+
+func (c *CarController) RestoreTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "ID tidak valid", http.StatusBadRequest)
+		return
+	}
+	if _, err := c.service.RestoreTask(requestContext(r), uint(id)); err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			http.Error(w, "Task tidak ada di trash", http.StatusNotFound)
+			return
+		}
+		log.Printf("Gagal memulihkan task ID %d: %v", id, err)
+		http.Error(w, "Gagal memulihkan task", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/trash", http.StatusSeeOther)
+}
+
+func (c *CarController) PurgeTask(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, err := strconv.ParseUint(ps.ByName("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "ID tidak valid", http.StatusBadRequest)
+		return
+	}
+	if err := c.service.PurgeTask(requestContext(r), uint(id)); err != nil {
+		if errors.Is(err, services.ErrTaskNotFound) {
+			http.Error(w, "Task tidak ada di trash", http.StatusNotFound)
+			return
+		}
+		log.Printf("Gagal menghapus permanen task ID %d: %v", id, err)
+		http.Error(w, "Gagal menghapus permanen task", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/trash", http.StatusSeeOther)
+}
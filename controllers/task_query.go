@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+// parseTaskQuery membaca filter daftar task dari query string sehingga
+// tampilan yang sudah difilter bisa di-bookmark. Dipakai oleh halaman HTML
+// maupun API.
+//
+//	?status=todo&tipe=Website&tag=go,api&q=belajar
+//	&created_from=2024-01-01&created_to=2024-01-31
+//	&updated_from=...&updated_to=...
+//	&sort=updated_at&order=desc&limit=20&cursor=...
+func parseTaskQuery(values url.Values) (models.TaskQuery, error) {
+	query := models.TaskQuery{
+		Status:  values.Get("status"),
+		Tipe:    values.Get("tipe"),
+		Search:  values.Get("q"),
+		SortBy:  values.Get("sort"),
+		SortDir: values.Get("order"),
+		Cursor:  values.Get("cursor"),
+	}
+	// tag boleh diulang (?tag=go&tag=api) atau dipisah koma; semua harus cocok.
+	for _, v := range values["tag"] {
+		query.Tags = append(query.Tags, models.ParseTagNames(v)...)
+	}
+	// "all" dipakai oleh dropdown filter di halaman utama.
+	if query.Status == "all" {
+		query.Status = ""
+	}
+	if query.Tipe == "all" {
+		query.Tipe = ""
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return query, fmt.Errorf("%w: limit harus berupa angka", services.ErrInvalidQuery)
+		}
+		query.Limit = limit
+	}
+
+	var err error
+	if query.CreatedFrom, err = parseQueryTime(values, "created_from", false); err != nil {
+		return query, err
+	}
+	if query.CreatedTo, err = parseQueryTime(values, "created_to", true); err != nil {
+		return query, err
+	}
+	if query.UpdatedFrom, err = parseQueryTime(values, "updated_from", false); err != nil {
+		return query, err
+	}
+	if query.UpdatedTo, err = parseQueryTime(values, "updated_to", true); err != nil {
+		return query, err
+	}
+	return query, nil
+}
+
+// parseQueryTime menerima RFC3339 atau tanggal saja (YYYY-MM-DD). Untuk batas
+// akhir, tanggal saja berarti sampai akhir hari tersebut.
+func parseQueryTime(values url.Values, key string, endOfDay bool) (*time.Time, error) {
+	v := values.Get(key)
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		t = t.Local()
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s harus berformat YYYY-MM-DD atau RFC3339", services.ErrInvalidQuery, key)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
+
+// isFiltered bernilai true jika query membatasi hasil, dipakai halaman utama
+// untuk membedakan "belum ada task" dengan "tidak ada yang cocok".
+func isFiltered(query models.TaskQuery) bool {
+	return query.Status != "" || query.Tipe != "" || len(query.Tags) > 0 || query.Search != "" ||
+		query.CreatedFrom != nil || query.CreatedTo != nil ||
+		query.UpdatedFrom != nil || query.UpdatedTo != nil || query.Cursor != ""
+}
+
+// nextPageURL menyalin query string saat ini dan mengganti cursor-nya.
+func nextPageURL(path string, values url.Values, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	next := url.Values{}
+	for k, v := range values {
+		next[k] = v
+	}
+	next.Set("cursor", cursor)
+	return path + "?" + next.Encode()
+}
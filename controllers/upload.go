@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+)
+
+const (
+	// multipartMemory adalah bagian form yang disimpan di memori; sisanya
+	// ditulis ke file sementara oleh net/http.
+	multipartMemory = 10 << 20
+	// multipartOverhead memberi ruang untuk field teks dan boundary di luar
+	// file cover itu sendiri.
+	multipartOverhead = 1 << 20
+)
+
+// parseUploadForm membatasi ukuran body sesuai batas upload service lalu
+// mem-parsing multipart form.
+func parseUploadForm(w http.ResponseWriter, r *http.Request, maxBytes int64) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBytes+multipartOverhead)
+	return r.ParseMultipartForm(multipartMemory)
+}
+
+func isTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
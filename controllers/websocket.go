@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+const (
+	// wsTypeOpenTerminal meminta server membuka proyek task di terminal.
+	wsTypeOpenTerminal = "open_terminal"
+	// wsTypeAck dan wsTypeError adalah balasan untuk setiap perintah.
+	wsTypeAck   = "ack"
+	wsTypeError = "error"
+	// wsTypeTaskEvent dikirim server tanpa diminta ketika task berubah.
+	wsTypeTaskEvent = "task_event"
+
+	// DefaultWebSocketPongWait adalah batas waktu menunggu pesan atau pong
+	// dari klien sebelum koneksi dianggap mati.
+	DefaultWebSocketPongWait = 60 * time.Second
+	wsWriteWait              = 10 * time.Second
+	wsMaxMessageBytes        = 4 << 10
+	// wsSendBuffer adalah jumlah pesan yang boleh mengantre untuk satu
+	// klien. Klien yang antreannya penuh diputus.
+	wsSendBuffer = 32
+)
+
+// wsRequest adalah perintah dari klien. ID dipilih klien dan dikembalikan
+// apa adanya di balasan supaya klien bisa mencocokkan keduanya.
+type wsRequest struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id"`
+	Payload json.RawMessage `json:"payload"`
+}
+
+// wsResponse dikirim tepat satu kali untuk setiap pesan dari klien.
+type wsResponse struct {
+	Type  string   `json:"type"`
+	ID    string   `json:"id,omitempty"`
+	OK    bool     `json:"ok"`
+	Data  any      `json:"data,omitempty"`
+	Error *wsError `json:"error,omitempty"`
+}
+
+// wsError memakai bentuk yang sama dengan error REST API.
+type wsError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// wsEvent dikirim hub ke klien tanpa didahului perintah.
+type wsEvent struct {
+	Type string `json:"type"`
+	Data any    `json:"data"`
+}
+
+type openTerminalPayload struct {
+	Launcher string `json:"launcher"`
+	// Terminal adalah nama lama untuk Launcher dari klien sebelum profil
+	// launcher bisa dikonfigurasi.
+	Terminal string `json:"terminal"`
+	TaskID   uint   `json:"task_id"`
+}
+
+func (p openTerminalPayload) launcher() string {
+	if p.Launcher != "" {
+		return p.Launcher
+	}
+	return p.Terminal
+}
+
+func wsAck(id string, data any) wsResponse {
+	return wsResponse{Type: wsTypeAck, ID: id, OK: true, Data: data}
+}
+
+func wsFailure(id, code, message string) wsResponse {
+	return wsResponse{Type: wsTypeError, ID: id, Error: &wsError{Code: code, Message: message}}
+}
+
+// wsClient adalah satu koneksi WebSocket. Hanya writePump yang menulis
+// pesan data ke conn; balasan perintah dan event hub masuk lewat send.
+type wsClient struct {
+	conn   *websocket.Conn
+	userID uint
+	send   chan any
+	stop   chan struct{}
+	// done ditutup ketika writePump berhenti.
+	done chan struct{}
+}
+
+func newWSClient(conn *websocket.Conn, userID uint) *wsClient {
+	return &wsClient{
+		conn:   conn,
+		userID: userID,
+		send:   make(chan any, wsSendBuffer),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// queue mengantrekan pesan untuk ditulis. Hasilnya false jika writer sudah
+// berhenti karena koneksi bermasalah.
+func (c *wsClient) queue(msg any) bool {
+	select {
+	case c.send <- msg:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// writePump menulis pesan dari send dan mengirim ping setiap pingPeriod
+// sampai close dipanggil atau penulisan gagal.
+func (c *wsClient) writePump(pingPeriod time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				log.Println("Gagal mengirim pesan WebSocket:", err)
+				c.conn.Close()
+				return
+			}
+		case <-ticker.C:
+			if err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				c.conn.Close()
+				return
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// close menghentikan writePump dan menunggunya selesai.
+func (c *wsClient) close() {
+	close(c.stop)
+	<-c.done
+}
+
+// readDeadline memasang read deadline yang diperpanjang setiap kali klien
+// mengirim pong. Fungsi yang dikembalikan dipanggil setelah setiap pesan.
+func readDeadline(conn *websocket.Conn, pongWait time.Duration) (extend func()) {
+	extend = func() {
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+	}
+	extend()
+	conn.SetPongHandler(func(string) error {
+		extend()
+		return nil
+	})
+	return extend
+}
+
+// Hub mencatat koneksi WebSocket yang aktif dan menyiarkan perubahan task ke
+// koneksi milik pemilik task. Hub memenuhi services.TaskNotifier.
+//
+// Koneksi WebSocket sudah di-hijack sehingga tidak ikut ditunggu
+// http.Server.Shutdown; CarController.CloseWebSockets menutupnya saat
+// aplikasi berhenti.
+type Hub struct {
+	mu      sync.Mutex
+	clients map[*wsClient]struct{}
+	closing bool
+	wg      sync.WaitGroup
+}
+
+func NewHub() *Hub {
+	return &Hub{clients: make(map[*wsClient]struct{})}
+}
+
+// add mendaftarkan client. Hasilnya false jika server sedang dimatikan dan
+// koneksi baru harus ditolak.
+func (h *Hub) add(client *wsClient) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closing {
+		return false
+	}
+	h.clients[client] = struct{}{}
+	h.wg.Add(1)
+	return true
+}
+
+func (h *Hub) remove(client *wsClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		h.wg.Done()
+	}
+}
+
+// TaskChanged mengirim perubahan ke semua koneksi milik pemilik task tanpa
+// menunggu penulisan. Koneksi yang antreannya penuh diputus supaya halaman
+// klien memuat ulang data, bukan diam-diam kehilangan perubahan.
+func (h *Hub) TaskChanged(change services.TaskChange) {
+	msg := wsEvent{Type: wsTypeTaskEvent, Data: change}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		if client.userID != change.OwnerID {
+			continue
+		}
+		select {
+		case client.send <- msg:
+		default:
+			log.Printf("Klien WebSocket pengguna ID %d terlalu lambat, koneksi diputus", client.userID)
+			client.conn.Close()
+		}
+	}
+}
+
+// closeAll mengirim close frame ke semua klien lalu menunggu handler-nya
+// selesai. Koneksi yang belum selesai ketika ctx habis diputus paksa.
+func (h *Hub) closeAll(ctx context.Context) error {
+	h.mu.Lock()
+	h.closing = true
+	conns := make([]*websocket.Conn, 0, len(h.clients))
+	for client := range h.clients {
+		conns = append(conns, client.conn)
+	}
+	h.mu.Unlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server dimatikan")
+	for _, conn := range conns {
+		conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		for _, conn := range conns {
+			conn.Close()
+		}
+		return ctx.Err()
+	}
+}
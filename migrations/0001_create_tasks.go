@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// task0001 adalah bentuk tabel tasks sebelum tag dinormalisasi.
+type task0001 struct {
+	ID          uint    `gorm:"primaryKey"`
+	Judul       string  `gorm:"type:varchar(255);not null"`
+	Status      string  `gorm:"type:varchar(50);not null;default:'todo'"`
+	Tipe        string  `gorm:"type:varchar(50);not null"`
+	PathProject *string `gorm:"type:text"`
+	LinkWebsite *string `gorm:"type:text"`
+	Tags        *string `gorm:"type:text"`
+	Catatan     string  `gorm:"type:text"`
+	Cover       string  `gorm:"type:varchar(255)"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (task0001) TableName() string { return "tasks" }
+
+var createTasks = Migration{
+	Version: 1,
+	Name:    "create_tasks",
+	Up: func(tx *gorm.DB) error {
+		// Database dari sebelum ada sistem migrasi sudah punya tabel ini.
+		if tx.Migrator().HasTable(&task0001{}) {
+			return nil
+		}
+		return tx.Migrator().CreateTable(&task0001{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&task0001{})
+	},
+}
@@ -0,0 +1,112 @@
+package migrations
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type tag0002 struct {
+	ID        uint   `gorm:"primaryKey"`
+	Name      string `gorm:"type:varchar(50);uniqueIndex;not null"`
+	CreatedAt time.Time
+}
+
+func (tag0002) TableName() string { return "tags" }
+
+type taskTag0002 struct {
+	TaskID uint `gorm:"primaryKey;autoIncrement:false"`
+	TagID  uint `gorm:"primaryKey;autoIncrement:false"`
+}
+
+func (taskTag0002) TableName() string { return "task_tags" }
+
+// parseTagNames0002 adalah salinan beku aturan normalisasi tag saat migrasi
+// ini dibuat: pisah koma, rapikan spasi, huruf kecil, tanpa duplikat dan
+// entri kosong, urutan dipertahankan.
+func parseTagNames0002(input string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, name := range strings.Split(input, ",") {
+		name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// createTags memindahkan kolom lama tasks.tags (string dipisah koma) ke
+// tabel tags/task_tags lalu menghapus kolom tersebut.
+var createTags = Migration{
+	Version: 2,
+	Name:    "create_tags",
+	Up: func(tx *gorm.DB) error {
+		for _, table := range []any{&tag0002{}, &taskTag0002{}} {
+			if tx.Migrator().HasTable(table) {
+				continue
+			}
+			if err := tx.Migrator().CreateTable(table); err != nil {
+				return err
+			}
+		}
+		if !tx.Migrator().HasColumn(&task0001{}, "tags") {
+			return nil
+		}
+
+		var rows []struct {
+			ID   uint
+			Tags *string
+		}
+		if err := tx.Table("tasks").Select("id, tags").Find(&rows).Error; err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if row.Tags == nil {
+				continue
+			}
+			for _, name := range parseTagNames0002(*row.Tags) {
+				tag := tag0002{Name: name}
+				if err := tx.Where("name = ?", name).FirstOrCreate(&tag).Error; err != nil {
+					return err
+				}
+				if err := tx.Create(&taskTag0002{TaskID: row.ID, TagID: tag.ID}).Error; err != nil {
+					return err
+				}
+			}
+		}
+		log.Printf("Migrasi tag: %d task diproses, kolom tasks.tags dihapus", len(rows))
+		return tx.Exec("ALTER TABLE tasks DROP COLUMN tags").Error
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().AddColumn(&task0001{}, "Tags"); err != nil {
+			return err
+		}
+		var rows []struct {
+			TaskID uint
+			Name   string
+		}
+		err := tx.Table("task_tags").
+			Select("task_tags.task_id, tags.name").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Order("task_tags.task_id, tags.name").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		names := map[uint][]string{}
+		for _, row := range rows {
+			names[row.TaskID] = append(names[row.TaskID], row.Name)
+		}
+		for taskID, list := range names {
+			if err := tx.Table("tasks").Where("id = ?", taskID).UpdateColumn("tags", strings.Join(list, ", ")).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Migrator().DropTable(&taskTag0002{}, &tag0002{})
+	},
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type taskEvent0003 struct {
+	ID        uint   `gorm:"primaryKey"`
+	TaskID    uint   `gorm:"index;not null"`
+	Operation string `gorm:"type:varchar(20);not null"`
+	Actor     string `gorm:"type:varchar(100)"`
+	Changes   string `gorm:"type:text"`
+	CreatedAt time.Time
+}
+
+func (taskEvent0003) TableName() string { return "task_events" }
+
+var createTaskEvents = Migration{
+	Version: 3,
+	Name:    "create_task_events",
+	Up: func(tx *gorm.DB) error {
+		if tx.Migrator().HasTable(&taskEvent0003{}) {
+			return nil
+		}
+		return tx.Migrator().CreateTable(&taskEvent0003{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&taskEvent0003{})
+	},
+}
@@ -0,0 +1,33 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type task0004 struct {
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (task0004) TableName() string { return "tasks" }
+
+var addTaskSoftDelete = Migration{
+	Version: 4,
+	Name:    "add_task_soft_delete",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if !m.HasColumn(&task0004{}, "deleted_at") {
+			if err := m.AddColumn(&task0004{}, "DeletedAt"); err != nil {
+				return err
+			}
+		}
+		if m.HasIndex(&task0004{}, "DeletedAt") {
+			return nil
+		}
+		return m.CreateIndex(&task0004{}, "DeletedAt")
+	},
+	// Task yang masih di trash akan muncul kembali sebagai task biasa.
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&task0004{}, "DeletedAt"); err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE tasks DROP COLUMN deleted_at").Error
+	},
+}
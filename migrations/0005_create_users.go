@@ -0,0 +1,47 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type user0005 struct {
+	ID           uint   `gorm:"primaryKey"`
+	Username     string `gorm:"type:varchar(64);uniqueIndex;not null"`
+	PasswordHash string `gorm:"type:varchar(255);not null"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (user0005) TableName() string { return "users" }
+
+type session0005 struct {
+	ID        string    `gorm:"type:varchar(64);primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	User      user0005  `gorm:"constraint:OnDelete:CASCADE"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
+
+func (session0005) TableName() string { return "sessions" }
+
+var createUsers = Migration{
+	Version: 5,
+	Name:    "create_users",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if !m.HasTable(&user0005{}) {
+			if err := m.CreateTable(&user0005{}); err != nil {
+				return err
+			}
+		}
+		if m.HasTable(&session0005{}) {
+			return nil
+		}
+		return m.CreateTable(&session0005{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&session0005{}, &user0005{})
+	},
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type task0006 struct {
+	OwnerID uint `gorm:"index;not null;default:0"`
+}
+
+func (task0006) TableName() string { return "tasks" }
+
+// addTaskOwner menambahkan pemilik task. Task yang sudah ada mendapat
+// owner_id 0 dan diadopsi oleh pengguna pertama yang dibuat.
+var addTaskOwner = Migration{
+	Version: 6,
+	Name:    "add_task_owner",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if !m.HasColumn(&task0006{}, "owner_id") {
+			if err := m.AddColumn(&task0006{}, "OwnerID"); err != nil {
+				return err
+			}
+		}
+		if m.HasIndex(&task0006{}, "OwnerID") {
+			return nil
+		}
+		return m.CreateIndex(&task0006{}, "OwnerID")
+	},
+	Down: func(tx *gorm.DB) error {
+		if err := tx.Migrator().DropIndex(&task0006{}, "OwnerID"); err != nil {
+			return err
+		}
+		return tx.Exec("ALTER TABLE tasks DROP COLUMN owner_id").Error
+	},
+}
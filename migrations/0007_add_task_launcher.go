@@ -0,0 +1,26 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type task0007 struct {
+	Launcher string `gorm:"type:varchar(64);not null;default:''"`
+}
+
+func (task0007) TableName() string { return "tasks" }
+
+// addTaskLauncher menyimpan profil launcher pilihan task. String kosong
+// berarti task belum memilih launcher.
+var addTaskLauncher = Migration{
+	Version: 7,
+	Name:    "add_task_launcher",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if m.HasColumn(&task0007{}, "launcher") {
+			return nil
+		}
+		return m.AddColumn(&task0007{}, "Launcher")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Exec("ALTER TABLE tasks DROP COLUMN launcher").Error
+	},
+}
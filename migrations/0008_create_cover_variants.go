@@ -0,0 +1,30 @@
+package migrations
+
+import "gorm.io/gorm"
+
+type coverVariant0008 struct {
+	ID     uint   `gorm:"primaryKey"`
+	TaskID uint   `gorm:"index;not null"`
+	Name   string `gorm:"type:varchar(20);not null"`
+	Path   string `gorm:"type:varchar(255);not null"`
+	Width  int    `gorm:"not null"`
+	Height int    `gorm:"not null"`
+}
+
+func (coverVariant0008) TableName() string { return "cover_variants" }
+
+// createCoverVariants menyimpan ukuran-ukuran cover. Cover lama tidak punya
+// varian dan tetap ditampilkan dari tasks.cover.
+var createCoverVariants = Migration{
+	Version: 8,
+	Name:    "create_cover_variants",
+	Up: func(tx *gorm.DB) error {
+		if tx.Migrator().HasTable(&coverVariant0008{}) {
+			return nil
+		}
+		return tx.Migrator().CreateTable(&coverVariant0008{})
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropTable(&coverVariant0008{})
+	},
+}
@@ -0,0 +1,126 @@
+package migrations
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type tag0009 struct {
+	ID        uint   `gorm:"primaryKey"`
+	OwnerID   uint   `gorm:"not null;default:0;uniqueIndex:idx_tags_owner_name"`
+	Name      string `gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_owner_name"`
+	CreatedAt time.Time
+}
+
+func (tag0009) TableName() string { return "tags" }
+
+const tagOwnerIndex0009 = "idx_tags_owner_name"
+
+// addTagOwner membuat tag menjadi milik pengguna. Tag yang dipakai task
+// beberapa pengguna dipecah menjadi satu tag per pemilik dengan nama yang
+// sama; tag yang tidak dipakai task mana pun mendapat owner_id 0 dan
+// diadopsi bersama task tanpa pemilik.
+var addTagOwner = Migration{
+	Version: 9,
+	Name:    "add_tag_owner",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if !m.HasColumn(&tag0009{}, "owner_id") {
+			if err := m.AddColumn(&tag0009{}, "OwnerID"); err != nil {
+				return err
+			}
+		}
+		if m.HasIndex(&tag0002{}, "Name") {
+			if err := m.DropIndex(&tag0002{}, "Name"); err != nil {
+				return err
+			}
+		}
+
+		// Trash ikut dihitung: task di trash tetap menyimpan tag-nya.
+		var rows []struct {
+			TagID   uint
+			OwnerID uint
+		}
+		err := tx.Table("task_tags").
+			Select("DISTINCT task_tags.tag_id, tasks.owner_id").
+			Joins("JOIN tasks ON tasks.id = task_tags.task_id").
+			Order("task_tags.tag_id, tasks.owner_id").
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		split := 0
+		for i, row := range rows {
+			if i == 0 || rows[i-1].TagID != row.TagID {
+				if err := tx.Table("tags").Where("id = ?", row.TagID).UpdateColumn("owner_id", row.OwnerID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			var tag tag0009
+			if err := tx.First(&tag, row.TagID).Error; err != nil {
+				return err
+			}
+			copied := tag0009{OwnerID: row.OwnerID, Name: tag.Name, CreatedAt: tag.CreatedAt}
+			if err := tx.Create(&copied).Error; err != nil {
+				return err
+			}
+			err := tx.Exec("UPDATE task_tags SET tag_id = ? WHERE tag_id = ? AND task_id IN (SELECT id FROM tasks WHERE owner_id = ?)",
+				copied.ID, row.TagID, row.OwnerID).Error
+			if err != nil {
+				return err
+			}
+			split++
+		}
+		if split > 0 {
+			log.Printf("Migrasi tag: %d tag baru dibuat untuk tag yang dipakai bersama", split)
+		}
+		if m.HasIndex(&tag0009{}, tagOwnerIndex0009) {
+			return nil
+		}
+		return m.CreateIndex(&tag0009{}, tagOwnerIndex0009)
+	},
+	Down: func(tx *gorm.DB) error {
+		// Tag bernama sama digabung ke tag dengan ID terkecil supaya nama
+		// kembali unik.
+		var tags []tag0009
+		if err := tx.Order("name, id").Find(&tags).Error; err != nil {
+			return err
+		}
+		for i := 1; i < len(tags); i++ {
+			keep := tags[i-1]
+			if tags[i].Name != keep.Name {
+				continue
+			}
+			dup := tags[i]
+			tags[i] = keep
+			var taskIDs []uint
+			err := tx.Table("task_tags").Where("tag_id = ?", dup.ID).
+				Where("task_id NOT IN (?)", tx.Table("task_tags").Select("task_id").Where("tag_id = ?", keep.ID)).
+				Pluck("task_id", &taskIDs).Error
+			if err != nil {
+				return err
+			}
+			for _, taskID := range taskIDs {
+				if err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?)", taskID, keep.ID).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", dup.ID).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&tag0009{}, dup.ID).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Migrator().DropIndex(&tag0009{}, tagOwnerIndex0009); err != nil {
+			return err
+		}
+		if err := tx.Exec("ALTER TABLE tags DROP COLUMN owner_id").Error; err != nil {
+			return err
+		}
+		return tx.Migrator().CreateIndex(&tag0002{}, "Name")
+	},
+}
@@ -0,0 +1,75 @@
+package migrations
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// Usage menjelaskan argumen subcommand migrate.
+const Usage = "pemakaian: migrate up | down | status | to <versi>"
+
+// RunCommand menjalankan subcommand migrate dan menulis hasilnya ke out.
+func RunCommand(r *Runner, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("migrate: perintah kosong, %s", Usage)
+	}
+	cmd, rest := args[0], args[1:]
+	switch {
+	case cmd == "up" && len(rest) == 0:
+		done, err := r.Up()
+		printDone(out, "dijalankan", done)
+		return err
+	case cmd == "down" && len(rest) == 0:
+		m, err := r.Down()
+		if m != nil {
+			printDone(out, "dibatalkan", []Migration{*m})
+		} else if err == nil {
+			fmt.Fprintln(out, "Belum ada migrasi yang dijalankan.")
+		}
+		return err
+	case cmd == "to" && len(rest) == 1:
+		version, err := strconv.ParseInt(rest[0], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("migrate: versi %q tidak valid", rest[0])
+		}
+		done, err := r.To(version)
+		printDone(out, "diproses", done)
+		return err
+	case cmd == "status" && len(rest) == 0:
+		return printStatus(r, out)
+	default:
+		return fmt.Errorf("migrate: argumen %q tidak dikenal, %s", args, Usage)
+	}
+}
+
+func printDone(out io.Writer, verb string, done []Migration) {
+	if len(done) == 0 {
+		fmt.Fprintln(out, "Skema sudah sesuai, tidak ada migrasi yang "+verb+".")
+		return
+	}
+	for _, m := range done {
+		fmt.Fprintf(out, "%04d %s %s\n", m.Version, m.Name, verb)
+	}
+}
+
+func printStatus(r *Runner, out io.Writer) error {
+	if err := r.Check(); err != nil {
+		return err
+	}
+	statuses, err := r.Status()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSI\tNAMA\tDIJALANKAN")
+	for _, st := range statuses {
+		applied := "tertunda"
+		if st.AppliedAt != nil {
+			applied = st.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", st.Version, st.Name, applied)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,272 @@
+// Package migrations berisi perubahan skema database yang berurutan dan
+// bisa dibatalkan. Setiap migrasi memakai struct salinannya sendiri (bukan
+// struct di package models) supaya hasilnya tidak berubah ketika model
+// berkembang.
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration adalah satu langkah perubahan skema. Up dan Down dijalankan di
+// dalam transaksi yang sama dengan pencatatan versinya.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// All berisi semua migrasi aplikasi, urut berdasarkan Version. Migrasi baru
+// selalu ditambahkan di akhir; migrasi yang sudah dirilis tidak boleh diubah.
+var All = []Migration{
+	createTasks,
+	createTags,
+	createTaskEvents,
+	addTaskSoftDelete,
+	createUsers,
+	addTaskOwner,
+	addTaskLauncher,
+	createCoverVariants,
+	addTagOwner,
+}
+
+var (
+	// ErrSchemaTooNew dikembalikan ketika database sudah dimigrasikan oleh
+	// versi aplikasi yang lebih baru.
+	ErrSchemaTooNew = errors.New("skema database lebih baru dari aplikasi ini")
+	// ErrUnknownVersion dikembalikan ketika target migrasi tidak dikenal.
+	ErrUnknownVersion = errors.New("versi migrasi tidak dikenal")
+)
+
+// schemaMigration mencatat migrasi yang sudah dijalankan.
+type schemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// Status menggambarkan satu migrasi dan kapan ia dijalankan; AppliedAt nil
+// berarti migrasi masih tertunda.
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+type Runner struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New membuat Runner untuk semua migrasi aplikasi.
+func New(db *gorm.DB) *Runner {
+	return NewRunner(db, All)
+}
+
+// NewRunner membuat Runner untuk daftar migrasi tertentu, misalnya di test.
+func NewRunner(db *gorm.DB, migrations []Migration) *Runner {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	return &Runner{db: db, migrations: sorted}
+}
+
+// Latest mengembalikan versi migrasi terbaru yang dikenal aplikasi.
+func (r *Runner) Latest() int64 {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+func (r *Runner) applied() (map[int64]schemaMigration, error) {
+	if err := r.db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, fmt.Errorf("migrasi: gagal menyiapkan schema_migrations: %w", err)
+	}
+	var rows []schemaMigration
+	if err := r.db.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+	return result, nil
+}
+
+// Check memastikan database tidak memuat migrasi yang tidak dikenal aplikasi.
+func (r *Runner) Check() error {
+	applied, err := r.applied()
+	if err != nil {
+		return err
+	}
+	return r.check(applied)
+}
+
+func (r *Runner) check(applied map[int64]schemaMigration) error {
+	for version, row := range applied {
+		if !r.known(version) {
+			return fmt.Errorf("%w: versi %d (%s) sudah dijalankan, versi terbaru aplikasi %d",
+				ErrSchemaTooNew, version, row.Name, r.Latest())
+		}
+	}
+	return nil
+}
+
+func (r *Runner) known(version int64) bool {
+	for _, m := range r.migrations {
+		if m.Version == version {
+			return true
+		}
+	}
+	return false
+}
+
+// Current mengembalikan versi tertinggi yang sudah dijalankan, 0 jika belum ada.
+func (r *Runner) Current() (int64, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return 0, err
+	}
+	var current int64
+	for version := range applied {
+		current = max(current, version)
+	}
+	return current, nil
+}
+
+func (r *Runner) Status() ([]Status, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(r.migrations))
+	for _, m := range r.migrations {
+		st := Status{Version: m.Version, Name: m.Name}
+		if row, ok := applied[m.Version]; ok {
+			appliedAt := row.AppliedAt
+			st.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
+
+// Pending mengembalikan migrasi yang belum dijalankan, urut dari yang tertua.
+func (r *Runner) Pending() ([]Migration, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range r.migrations {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// Up menjalankan semua migrasi yang tertunda dan mengembalikan yang dijalankan.
+func (r *Runner) Up() ([]Migration, error) {
+	return r.migrate(r.Latest())
+}
+
+// Down membatalkan satu migrasi terakhir. Hasilnya nil jika belum ada
+// migrasi yang dijalankan.
+func (r *Runner) Down() (*Migration, error) {
+	current, err := r.Current()
+	if err != nil || current == 0 {
+		return nil, err
+	}
+	target := int64(0)
+	for _, m := range r.migrations {
+		if m.Version < current {
+			target = m.Version
+		}
+	}
+	done, err := r.migrate(target)
+	if err != nil || len(done) == 0 {
+		return nil, err
+	}
+	return &done[0], nil
+}
+
+// To menjalankan atau membatalkan migrasi sampai skema berada di version.
+// Version 0 membatalkan semua migrasi.
+func (r *Runner) To(version int64) ([]Migration, error) {
+	if version != 0 && !r.known(version) {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+	return r.migrate(version)
+}
+
+// migrate menjalankan Up untuk migrasi tertunda sampai target dan Down untuk
+// migrasi di atas target (dari yang terbaru).
+func (r *Runner) migrate(target int64) ([]Migration, error) {
+	applied, err := r.applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.check(applied); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range r.migrations {
+		if _, ok := applied[m.Version]; ok || m.Version > target {
+			continue
+		}
+		if err := r.apply(m); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	for i := len(r.migrations) - 1; i >= 0; i-- {
+		m := r.migrations[i]
+		if _, ok := applied[m.Version]; !ok || m.Version <= target {
+			continue
+		}
+		if err := r.revert(m); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+func (r *Runner) apply(m Migration) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := m.Up(tx); err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("migrasi %d (%s) gagal: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+func (r *Runner) revert(m Migration) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if m.Down == nil {
+			return errors.New("migrasi ini tidak bisa dibatalkan")
+		}
+		if err := m.Down(tx); err != nil {
+			return err
+		}
+		return tx.Delete(&schemaMigration{}, m.Version).Error
+	})
+	if err != nil {
+		return fmt.Errorf("pembatalan migrasi %d (%s) gagal: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
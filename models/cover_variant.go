@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Nama varian cover. Path varian CoverFull juga disimpan di Task.Cover.
+const (
+	CoverThumb  = "thumb"
+	CoverMedium = "medium"
+	CoverFull   = "full"
+)
+
+// CoverVariant adalah satu ukuran cover task yang sudah di-resize.
+type CoverVariant struct {
+	ID     uint   `gorm:"primaryKey" json:"-"`
+	TaskID uint   `gorm:"index;not null" json:"-"`
+	Name   string `gorm:"type:varchar(20);not null" json:"name"`
+	// Path adalah URL file, sama bentuknya dengan Task.Cover.
+	Path   string `gorm:"type:varchar(255);not null" json:"path"`
+	Width  int    `gorm:"not null" json:"width"`
+	Height int    `gorm:"not null" json:"height"`
+}
+
+// CoverVariant mengembalikan varian cover bernama name, atau nil jika task
+// tidak punya varian itu (misalnya cover yang diunggah sebelum ada varian).
+func (t Task) CoverVariant(name string) *CoverVariant {
+	for i := range t.CoverVariants {
+		if t.CoverVariants[i].Name == name {
+			return &t.CoverVariants[i]
+		}
+	}
+	return nil
+}
+
+// CoverSrcset menyusun atribut srcset dari varian cover, dari yang paling
+// sempit. Varian dengan lebar yang sama hanya ditulis sekali.
+func (t Task) CoverSrcset() string {
+	variants := append([]CoverVariant(nil), t.CoverVariants...)
+	sort.SliceStable(variants, func(i, j int) bool { return variants[i].Width < variants[j].Width })
+	var parts []string
+	last := 0
+	for _, v := range variants {
+		if v.Width == last {
+			continue
+		}
+		last = v.Width
+		parts = append(parts, fmt.Sprintf("%s %dw", v.Path, v.Width))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CoverPaths mengembalikan URL semua file cover task tanpa duplikat.
+func (t Task) CoverPaths() []string {
+	var paths []string
+	seen := map[string]bool{"": true}
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	add(t.Cover)
+	for _, v := range t.CoverVariants {
+		add(v.Path)
+	}
+	return paths
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// GitStatus adalah ringkasan repositori git di PathProject sebuah task.
+type GitStatus struct {
+	// Repo false berarti PathProject bukan working tree git; field lain
+	// selain CheckedAt kosong.
+	Repo bool `json:"repo"`
+	// Branch kosong jika HEAD terlepas (detached).
+	Branch string `json:"branch,omitempty"`
+	// Dirty adalah jumlah file yang berubah, termasuk file yang belum
+	// dilacak.
+	Dirty int `json:"dirty"`
+	// Upstream kosong jika branch tidak melacak branch remote; Ahead dan
+	// Behind hanya berarti jika Upstream terisi.
+	Upstream   string     `json:"upstream,omitempty"`
+	Ahead      int        `json:"ahead"`
+	Behind     int        `json:"behind"`
+	LastCommit *GitCommit `json:"last_commit,omitempty"`
+	// CheckedAt adalah waktu git terakhir dijalankan untuk status ini.
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+type GitCommit struct {
+	Hash    string    `json:"hash"`
+	Message string    `json:"message"`
+	Date    time.Time `json:"date"`
+}
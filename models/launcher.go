@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LauncherPathPlaceholder diganti dengan direktori proyek di Args dan Env.
+const LauncherPathPlaceholder = "{path}"
+
+// MaxLauncherNameLength sama dengan panjang kolom tasks.launcher.
+const MaxLauncherNameLength = 64
+
+var launcherNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// Launcher adalah profil untuk membuka direktori proyek, misalnya editor
+// atau terminal. Executable dijalankan langsung dengan Args sebagai argv,
+// tanpa shell, di dalam direktori proyek.
+//
+// Jangan memakai {path} di dalam argumen `sh -c`; placeholder hanya aman
+// sebagai argumen tersendiri atau bagian dari argumen biasa seperti
+// --cwd={path}.
+type Launcher struct {
+	// Name adalah pengenal yang disimpan di task dan dikirim klien.
+	Name       string            `yaml:"name" json:"name"`
+	Label      string            `yaml:"label" json:"label"`
+	Executable string            `yaml:"executable" json:"executable"`
+	Args       []string          `yaml:"args" json:"args"`
+	Env        map[string]string `yaml:"env" json:"env,omitempty"`
+}
+
+// Title mengembalikan Label, atau Name jika Label kosong.
+func (l Launcher) Title() string {
+	if l.Label != "" {
+		return l.Label
+	}
+	return l.Name
+}
+
+// Argv mengembalikan Args dengan setiap {path} diganti dir.
+func (l Launcher) Argv(dir string) []string {
+	argv := make([]string, len(l.Args))
+	for i, arg := range l.Args {
+		argv[i] = strings.ReplaceAll(arg, LauncherPathPlaceholder, dir)
+	}
+	return argv
+}
+
+// Environ mengembalikan Env dalam bentuk KEY=value, urut menurut nama,
+// dengan {path} diganti dir.
+func (l Launcher) Environ(dir string) []string {
+	env := make([]string, 0, len(l.Env))
+	for key, value := range l.Env {
+		env = append(env, key+"="+strings.ReplaceAll(value, LauncherPathPlaceholder, dir))
+	}
+	sort.Strings(env)
+	return env
+}
+
+func (l Launcher) Validate() error {
+	switch {
+	case len(l.Name) > MaxLauncherNameLength || !launcherNamePattern.MatchString(l.Name):
+		return fmt.Errorf("launcher: nama %q harus huruf kecil, angka, titik, garis bawah atau strip (maksimal %d karakter)", l.Name, MaxLauncherNameLength)
+	case strings.TrimSpace(l.Executable) == "":
+		return fmt.Errorf("launcher %q: executable tidak boleh kosong", l.Name)
+	}
+	for key := range l.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return fmt.Errorf("launcher %q: nama env %q tidak valid", l.Name, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// MaxTagNameLength adalah panjang maksimum nama tag setelah dinormalisasi.
+const MaxTagNameLength = 50
+
+// Tag dimiliki satu pengguna; nama tag hanya unik per pemilik sehingga
+// dua pengguna bisa punya tag "go" masing-masing.
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OwnerID   uint      `gorm:"not null;default:0;uniqueIndex:idx_tags_owner_name" json:"-"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_owner_name" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// TaskCount hanya diisi oleh query daftar tag (jumlah task yang memakai tag ini).
+	TaskCount int64 `gorm:"->;-:migration" json:"task_count"`
+}
+
+// NormalizeTagName membuat "  React  JS " dan "react js" menjadi tag yang sama.
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// ParseTagNames memecah input tag dipisah koma (format form lama) menjadi
+// nama tag yang sudah dinormalisasi, tanpa duplikat dan tanpa entri kosong.
+func ParseTagNames(input string) []string {
+	return NormalizeTagNames(strings.Split(input, ","))
+}
+
+// NormalizeTagNames menormalisasi setiap nama dan membuang duplikat serta
+// entri kosong dengan tetap menjaga urutan.
+func NormalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// TagsFromNames membungkus nama tag menjadi []Tag yang belum tersimpan;
+// service yang akan mencari atau membuat tag sebenarnya.
+func TagsFromNames(names []string) []Tag {
+	tags := make([]Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, Tag{Name: name})
+	}
+	return tags
+}
+
+// TagNames mengembalikan nama-nama tag dari sebuah task.
+func TagNames(tags []Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
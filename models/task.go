@@ -1,17 +1,33 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Task struct {
-	ID          uint    `gorm:"primaryKey"`
-	Judul       string  `gorm:"type:varchar(255);not null"`
-	Status      string  `gorm:"type:varchar(50);not null;default:'todo'"`
-	Tipe        string  `gorm:"type:varchar(50);not null"`
-	PathProject *string `gorm:"type:text"`
-	LinkWebsite *string `gorm:"type:text"`
-	Tags        string  `gorm:"type:text"`
-	Catatan     string  `gorm:"type:text"`
-	Cover       string  `gorm:"type:varchar(255)"`
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID          uint    `gorm:"primaryKey" json:"id"`
+	Judul       string  `gorm:"type:varchar(255);not null" json:"judul"`
+	Status      string  `gorm:"type:varchar(50);not null;default:'todo'" json:"status"`
+	Tipe        string  `gorm:"type:varchar(50);not null" json:"tipe"`
+	PathProject *string `gorm:"type:text" json:"path_project"`
+	LinkWebsite *string `gorm:"type:text" json:"link_website"`
+	Tags        []Tag   `gorm:"many2many:task_tags" json:"tags"`
+	Catatan     string  `gorm:"type:text" json:"catatan"`
+	Cover       string  `gorm:"type:varchar(255)" json:"cover"`
+	// CoverVariants adalah ukuran-ukuran Cover; kosong untuk task tanpa
+	// cover.
+	CoverVariants []CoverVariant `gorm:"foreignKey:TaskID" json:"cover_variants"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	// DeletedAt terisi ketika task dipindahkan ke trash; GORM otomatis
+	// menyembunyikan task tersebut dari query biasa.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+	// OwnerID adalah pemilik task; 0 berarti task lama yang belum diadopsi
+	// pengguna pertama.
+	OwnerID uint `gorm:"index;not null;default:0" json:"owner_id"`
+	// Launcher adalah nama profil launcher yang dipakai membuka proyek
+	// (lihat models.Launcher); kosong berarti dipilih saat membuka.
+	Launcher string `gorm:"type:varchar(64);not null;default:''" json:"launcher"`
 }
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Operasi yang dicatat pada TaskEvent.
+const (
+	TaskEventCreate  = "create"
+	TaskEventUpdate  = "update"
+	TaskEventDelete  = "delete"
+	TaskEventCover   = "cover"
+	TaskEventRestore = "restore"
+	TaskEventPurge   = "purge"
+)
+
+// TaskEvent adalah satu entri riwayat perubahan sebuah task.
+type TaskEvent struct {
+	ID        uint         `gorm:"primaryKey" json:"id"`
+	TaskID    uint         `gorm:"index;not null" json:"task_id"`
+	Operation string       `gorm:"type:varchar(20);not null" json:"operation"`
+	Actor     string       `gorm:"type:varchar(100)" json:"actor"`
+	Changes   FieldChanges `gorm:"type:text" json:"changes"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// FieldChanges disimpan sebagai JSON di satu kolom teks.
+type FieldChanges []FieldChange
+
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(c)
+	return string(data), err
+}
+
+func (c *FieldChanges) Scan(value any) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("FieldChanges: tipe %T tidak didukung", value)
+	}
+	return json.Unmarshal(data, c)
+}
+
+// DiffTask membandingkan field yang bisa diubah pengguna dan mengembalikan
+// perubahan yang terjadi dari before ke after. Tags dibandingkan sebagai
+// daftar nama yang dipisah koma.
+func DiffTask(before, after *Task) FieldChanges {
+	var changes FieldChanges
+	add := func(field, old, new string) {
+		if old != new {
+			changes = append(changes, FieldChange{Field: field, Old: old, New: new})
+		}
+	}
+	add("judul", before.Judul, after.Judul)
+	add("status", before.Status, after.Status)
+	add("tipe", before.Tipe, after.Tipe)
+	add("path_project", derefString(before.PathProject), derefString(after.PathProject))
+	add("launcher", before.Launcher, after.Launcher)
+	add("link_website", derefString(before.LinkWebsite), derefString(after.LinkWebsite))
+	add("tags", strings.Join(TagNames(before.Tags), ", "), strings.Join(TagNames(after.Tags), ", "))
+	add("catatan", before.Catatan, after.Catatan)
+	add("cover", before.Cover, after.Cover)
+	return changes
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
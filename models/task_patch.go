@@ -0,0 +1,124 @@
+package models
+
+import "encoding/json"
+
+// PatchField adalah satu field TaskPatch. Set false berarti field tidak
+// diubah; Set true dengan Value nol berarti field dikosongkan.
+type PatchField[T any] struct {
+	Set   bool
+	Value T
+}
+
+// SetField mengisi field dengan value; value nol mengosongkan field.
+func SetField[T any](value T) PatchField[T] {
+	return PatchField[T]{Set: true, Value: value}
+}
+
+// UnmarshalJSON menandai field yang muncul di JSON sebagai Set. null
+// mengosongkan field, field yang tidak ada di JSON tidak diubah.
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		var zero T
+		f.Value = zero
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+// TaskPatch adalah perubahan sebagian pada task. Berbeda dengan Updates
+// GORM dari struct Task, field yang Set dengan value kosong benar-benar
+// dikosongkan: PathProject dan LinkWebsite menjadi NULL, Tags menjadi
+// kosong.
+type TaskPatch struct {
+	Judul       PatchField[string]   `json:"judul"`
+	Status      PatchField[string]   `json:"status"`
+	Tipe        PatchField[string]   `json:"tipe"`
+	PathProject PatchField[string]   `json:"path_project"`
+	LinkWebsite PatchField[string]   `json:"link_website"`
+	Tags        PatchField[[]string] `json:"tags"`
+	Catatan     PatchField[string]   `json:"catatan"`
+	Launcher    PatchField[string]   `json:"launcher"`
+}
+
+// PatchFromTask mengikuti semantik lama UpdateTask: field kosong dan Tags
+// nil tidak diubah.
+func PatchFromTask(task *Task) *TaskPatch {
+	patch := &TaskPatch{}
+	setIf := func(f *PatchField[string], value string) {
+		if value != "" {
+			*f = SetField(value)
+		}
+	}
+	setIf(&patch.Judul, task.Judul)
+	setIf(&patch.Status, task.Status)
+	setIf(&patch.Tipe, task.Tipe)
+	if task.PathProject != nil {
+		setIf(&patch.PathProject, *task.PathProject)
+	}
+	if task.LinkWebsite != nil {
+		setIf(&patch.LinkWebsite, *task.LinkWebsite)
+	}
+	if task.Tags != nil {
+		patch.Tags = SetField(TagNames(task.Tags))
+	}
+	setIf(&patch.Catatan, task.Catatan)
+	setIf(&patch.Launcher, task.Launcher)
+	return patch
+}
+
+// ReplacePatchFromTask mengganti seluruh isi task dengan task, seperti PUT:
+// field opsional yang kosong dan Tags nil dikosongkan. Status kosong tidak
+// diubah karena perubahan status mengikuti workflow, dan cover diganti
+// lewat file upload.
+func ReplacePatchFromTask(task *Task) *TaskPatch {
+	patch := &TaskPatch{
+		Judul:       SetField(task.Judul),
+		Tipe:        SetField(task.Tipe),
+		PathProject: SetField(""),
+		LinkWebsite: SetField(""),
+		Tags:        SetField(TagNames(task.Tags)),
+		Catatan:     SetField(task.Catatan),
+		Launcher:    SetField(task.Launcher),
+	}
+	if task.Status != "" {
+		patch.Status = SetField(task.Status)
+	}
+	if task.PathProject != nil {
+		patch.PathProject = SetField(*task.PathProject)
+	}
+	if task.LinkWebsite != nil {
+		patch.LinkWebsite = SetField(*task.LinkWebsite)
+	}
+	return patch
+}
+
+// Apply menyalin field yang Set ke task, kecuali Tags yang disimpan lewat
+// tabel join, dan mengembalikan nama kolom yang berubah.
+func (p *TaskPatch) Apply(task *Task) []string {
+	var columns []string
+	setString := func(column string, f PatchField[string], dst *string) {
+		if f.Set {
+			*dst = f.Value
+			columns = append(columns, column)
+		}
+	}
+	setNullable := func(column string, f PatchField[string], dst **string) {
+		if f.Set {
+			*dst = nil
+			if f.Value != "" {
+				value := f.Value
+				*dst = &value
+			}
+			columns = append(columns, column)
+		}
+	}
+	setString("judul", p.Judul, &task.Judul)
+	setString("status", p.Status, &task.Status)
+	setString("tipe", p.Tipe, &task.Tipe)
+	setNullable("path_project", p.PathProject, &task.PathProject)
+	setNullable("link_website", p.LinkWebsite, &task.LinkWebsite)
+	setString("catatan", p.Catatan, &task.Catatan)
+	setString("launcher", p.Launcher, &task.Launcher)
+	return columns
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultTaskQueryLimit = 50
+	MaxTaskQueryLimit     = 200
+)
+
+// TaskSortFields adalah daftar kolom yang boleh dipakai untuk mengurutkan task.
+var TaskSortFields = map[string]string{
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"judul":      "judul",
+	"status":     "status",
+	"tipe":       "tipe",
+}
+
+// TaskQuery menampung filter, pencarian, pengurutan dan paginasi untuk
+// daftar task. Field kosong berarti tidak difilter.
+type TaskQuery struct {
+	Status string
+	Tipe   string
+	// Tags: task harus memiliki semua tag ini (nama sudah dinormalisasi).
+	Tags []string
+	// Search dicocokkan (case-insensitive) dengan Judul dan Catatan.
+	Search string
+
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+
+	SortBy  string // salah satu key TaskSortFields, default created_at
+	SortDir string // asc atau desc, default asc
+
+	Limit  int
+	Cursor string
+}
+
+// TaskPage adalah satu halaman hasil TaskQuery. NextCursor kosong jika tidak
+// ada halaman berikutnya.
+type TaskPage struct {
+	Tasks      []Task `json:"data"`
+	Total      int64  `json:"total"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// EncodeTaskCursor membungkus offset menjadi cursor yang opaque bagi klien.
+func EncodeTaskCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("o:" + strconv.Itoa(offset)))
+}
+
+// DecodeTaskCursor adalah kebalikan dari EncodeTaskCursor. Cursor kosong
+// berarti halaman pertama.
+func DecodeTaskCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), "o:") {
+		return 0, errors.New("cursor tidak valid")
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), "o:"))
+	if err != nil || offset < 0 {
+		return 0, errors.New("cursor tidak valid")
+	}
+	return offset, nil
+}
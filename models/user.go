@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+const (
+	MinUsernameLength = 3
+	MaxUsernameLength = 64
+	MinPasswordLength = 8
+)
+
+// User adalah akun lokal yang memiliki task.
+type User struct {
+	ID       uint   `gorm:"primaryKey" json:"id"`
+	Username string `gorm:"type:varchar(64);uniqueIndex;not null" json:"username"`
+	// PasswordHash berisi hash bcrypt, tidak pernah dikirim ke klien.
+	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// Session adalah sesi login yang diwakili cookie. ID berisi hash SHA-256
+// dari token di cookie sehingga isi tabel ini tidak bisa dipakai untuk login.
+type Session struct {
+	ID        string    `gorm:"type:varchar(64);primaryKey"`
+	UserID    uint      `gorm:"index;not null"`
+	User      User      `gorm:"constraint:OnDelete:CASCADE"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time
+}
+
+// Expired melaporkan apakah sesi sudah tidak berlaku pada waktu now.
+func (s *Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
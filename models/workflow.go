@@ -0,0 +1,132 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+type StatusDefinition struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	// Color dipakai sebagai warna border kartu dan badge di halaman utama.
+	Color string `json:"color"`
+	// Done menandai status yang dihitung sebagai "selesai" pada statistik.
+	Done bool `json:"done"`
+}
+
+// Workflow mendefinisikan status yang boleh dipakai task dan perpindahan
+// status yang diizinkan. Berpindah ke status yang sama selalu diizinkan.
+type Workflow struct {
+	Initial     string              `json:"initial"`
+	Statuses    []StatusDefinition  `json:"statuses"`
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// DefaultWorkflow mempertahankan perilaku lama: todo, inprogress dan done
+// dan bebas berpindah di antara ketiganya.
+func DefaultWorkflow() *Workflow {
+	return &Workflow{
+		Initial: "todo",
+		Statuses: []StatusDefinition{
+			{Key: "todo", Label: "⏳ todo", Color: "#9ca3af"},
+			{Key: "inprogress", Label: "🔄 in progress", Color: "#f59e0b"},
+			{Key: "done", Label: "✅ done", Color: "#10b981", Done: true},
+		},
+		Transitions: map[string][]string{
+			"todo":       {"inprogress", "done"},
+			"inprogress": {"todo", "done"},
+			"done":       {"todo", "inprogress"},
+		},
+	}
+}
+
+// Validate memastikan workflow konsisten: status unik, initial dan semua
+// transisi mengacu ke status yang terdaftar.
+func (w *Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return errors.New("workflow: minimal satu status harus didefinisikan")
+	}
+	seen := make(map[string]bool, len(w.Statuses))
+	for _, st := range w.Statuses {
+		if st.Key == "" {
+			return errors.New("workflow: key status tidak boleh kosong")
+		}
+		if seen[st.Key] {
+			return fmt.Errorf("workflow: status %q didefinisikan lebih dari sekali", st.Key)
+		}
+		seen[st.Key] = true
+	}
+	if !seen[w.Initial] {
+		return fmt.Errorf("workflow: status awal %q tidak terdaftar", w.Initial)
+	}
+	for from, targets := range w.Transitions {
+		if !seen[from] {
+			return fmt.Errorf("workflow: transisi dari status tidak dikenal %q", from)
+		}
+		for _, to := range targets {
+			if !seen[to] {
+				return fmt.Errorf("workflow: transisi %q -> %q ke status tidak dikenal", from, to)
+			}
+		}
+	}
+	return nil
+}
+
+func (w *Workflow) Status(key string) (StatusDefinition, bool) {
+	for _, st := range w.Statuses {
+		if st.Key == key {
+			return st, true
+		}
+	}
+	return StatusDefinition{}, false
+}
+
+func (w *Workflow) IsValid(key string) bool {
+	_, ok := w.Status(key)
+	return ok
+}
+
+// CanTransition melaporkan apakah task boleh berpindah dari from ke to.
+// Task dengan status yang sudah tidak ada di workflow boleh dipindahkan ke
+// status valid mana pun supaya tidak terkunci setelah konfigurasi berubah.
+func (w *Workflow) CanTransition(from, to string) bool {
+	if !w.IsValid(to) {
+		return false
+	}
+	if from == to || !w.IsValid(from) {
+		return true
+	}
+	for _, target := range w.Transitions[from] {
+		if target == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Next mengembalikan status yang boleh dipilih untuk task berstatus from,
+// termasuk from itu sendiri, sesuai urutan di Statuses.
+func (w *Workflow) Next(from string) []StatusDefinition {
+	var result []StatusDefinition
+	for _, st := range w.Statuses {
+		if w.CanTransition(from, st.Key) {
+			result = append(result, st)
+		}
+	}
+	return result
+}
+
+// Label dan Color dipakai template; status tak dikenal ditampilkan apa adanya.
+func (w *Workflow) Label(key string) string {
+	if st, ok := w.Status(key); ok && st.Label != "" {
+		return st.Label
+	}
+	return key
+}
+
+func (w *Workflow) Color(key string) string {
+	if st, ok := w.Status(key); ok && st.Color != "" {
+		return st.Color
+	}
+	return "#9ca3af"
+}
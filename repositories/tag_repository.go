@@ -0,0 +1,184 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+)
+
+type TagRepository interface {
+	FindAllWithCount() ([]models.Tag, error)
+	FindByID(id uint) (*models.Tag, error)
+	FindByName(name string) (*models.Tag, error)
+	Create(tag *models.Tag) (*models.Tag, error)
+	UpdateWithTx(tag *models.Tag, tx *gorm.DB) error
+	Delete(id uint) error
+	DeleteWithTx(id uint, tx *gorm.DB) error
+	Merge(sourceID, targetID uint) error
+	MergeWithTx(sourceID, targetID uint, tx *gorm.DB) error
+	// FindTaskIDsWithTx mengembalikan ID task yang memakai salah satu tagIDs,
+	// termasuk task di trash.
+	FindTaskIDsWithTx(tagIDs []uint, tx *gorm.DB) ([]uint, error)
+	// TaskTagNamesWithTx mengembalikan nama tag setiap task, urut nama.
+	// Task tanpa tag tidak ada di map.
+	TaskTagNamesWithTx(taskIDs []uint, tx *gorm.DB) (map[uint][]string, error)
+	// WithOwner mengembalikan repository yang hanya melihat dan mengubah
+	// tag milik ownerID. Tag yang dibuat lewat repository itu otomatis
+	// dimiliki ownerID.
+	WithOwner(ownerID uint) TagRepository
+	GetDB() *gorm.DB
+}
+
+type TagRepositoryImpl struct {
+	db *gorm.DB
+	// ownerID 0 berarti tidak dibatasi.
+	ownerID uint
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &TagRepositoryImpl{db: db}
+}
+
+func (r *TagRepositoryImpl) GetDB() *gorm.DB {
+	return r.db
+}
+
+func (r *TagRepositoryImpl) WithOwner(ownerID uint) TagRepository {
+	return &TagRepositoryImpl{db: r.db, ownerID: ownerID}
+}
+
+// owned membatasi query pada tag milik ownerID jika repository dibatasi.
+func (r *TagRepositoryImpl) owned(db *gorm.DB) *gorm.DB {
+	if r.ownerID == 0 {
+		return db
+	}
+	return db.Where("tags.owner_id = ?", r.ownerID)
+}
+
+// FindAllWithCount mengembalikan tag beserta jumlah task yang memakainya.
+// Task yang berada di trash tidak dihitung.
+func (r *TagRepositoryImpl) FindAllWithCount() ([]models.Tag, error) {
+	var tags []models.Tag
+	err := r.owned(r.db.Model(&models.Tag{})).
+		Select("tags.*, COUNT(tasks.id) AS task_count").
+		Joins("LEFT JOIN task_tags ON task_tags.tag_id = tags.id").
+		Joins("LEFT JOIN tasks ON tasks.id = task_tags.task_id AND tasks.deleted_at IS NULL").
+		Group("tags.id").
+		Order("tags.name").
+		Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepositoryImpl) FindByID(id uint) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.owned(r.db).First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepositoryImpl) FindByName(name string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.owned(r.db).Where("name = ?", name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *TagRepositoryImpl) Create(tag *models.Tag) (*models.Tag, error) {
+	if r.ownerID != 0 {
+		tag.OwnerID = r.ownerID
+	}
+	err := r.db.Create(tag).Error
+	return tag, err
+}
+
+func (r *TagRepositoryImpl) UpdateWithTx(tag *models.Tag, tx *gorm.DB) error {
+	return tx.Save(tag).Error
+}
+
+// Delete menghapus tag dan melepaskannya dari semua task.
+func (r *TagRepositoryImpl) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return r.DeleteWithTx(id, tx)
+	})
+}
+
+func (r *TagRepositoryImpl) DeleteWithTx(id uint, tx *gorm.DB) error {
+	if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", id).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.Tag{}, id).Error
+}
+
+// Merge memindahkan semua task dari tag sourceID ke targetID lalu menghapus
+// sourceID. Task yang sudah punya kedua tag tidak akan terduplikasi.
+func (r *TagRepositoryImpl) Merge(sourceID, targetID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return r.MergeWithTx(sourceID, targetID, tx)
+	})
+}
+
+func (r *TagRepositoryImpl) MergeWithTx(sourceID, targetID uint, tx *gorm.DB) error {
+	// Dikerjakan dengan query sederhana supaya sama di semua database.
+	var taskIDs []uint
+	err := tx.Table("task_tags").Where("tag_id = ?", sourceID).
+		Where("task_id NOT IN (?)", tx.Table("task_tags").Select("task_id").Where("tag_id = ?", targetID)).
+		Pluck("task_id", &taskIDs).Error
+	if err != nil {
+		return err
+	}
+	for _, taskID := range taskIDs {
+		if err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?)", taskID, targetID).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Exec("DELETE FROM task_tags WHERE tag_id = ?", sourceID).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.Tag{}, sourceID).Error
+}
+
+func (r *TagRepositoryImpl) FindTaskIDsWithTx(tagIDs []uint, tx *gorm.DB) ([]uint, error) {
+	var taskIDs []uint
+	err := tx.Table("task_tags").Distinct("task_id").Where("tag_id IN ?", tagIDs).
+		Order("task_id").Pluck("task_id", &taskIDs).Error
+	return taskIDs, err
+}
+
+func (r *TagRepositoryImpl) TaskTagNamesWithTx(taskIDs []uint, tx *gorm.DB) (map[uint][]string, error) {
+	var rows []struct {
+		TaskID uint
+		Name   string
+	}
+	err := tx.Table("task_tags").
+		Select("task_tags.task_id, tags.name").
+		Joins("JOIN tags ON tags.id = task_tags.tag_id").
+		Where("task_tags.task_id IN ?", taskIDs).
+		Order("task_tags.task_id, tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[uint][]string, len(taskIDs))
+	for _, row := range rows {
+		names[row.TaskID] = append(names[row.TaskID], row.Name)
+	}
+	return names, nil
+}
+
+// FindOrCreateTags mengembalikan tag milik ownerID untuk setiap nama (dengan
+// urutan yang sama), membuat tag yang belum ada di dalam tx yang diberikan.
+func FindOrCreateTags(tx *gorm.DB, ownerID uint, names []string) ([]models.Tag, error) {
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		// Kondisi ditulis eksplisit; struct kondisi GORM mengabaikan
+		// owner_id 0.
+		tag := models.Tag{OwnerID: ownerID, Name: name}
+		if err := tx.Where("owner_id = ? AND name = ?", ownerID, name).FirstOrCreate(&tag).Error; err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
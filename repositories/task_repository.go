@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
 
 	"github.com/nabilulilalbab/welcomesite/models"
@@ -8,16 +11,41 @@ import (
 
 type TaskRepository interface {
 	Create(task *models.Task) (*models.Task, error)
+	CreateWithTx(task *models.Task, tx *gorm.DB) error
 	FindByID(id uint) (*models.Task, error)
 	FindAll() ([]models.Task, error)
+	FindByQuery(query models.TaskQuery) (*models.TaskPage, error)
 	Update(task *models.Task) (*models.Task, error)
 	Delete(id uint) error
+	DeleteWithTx(id uint, tx *gorm.DB) error
+	FindEvents(taskID uint) ([]models.TaskEvent, error)
 	GetDB() *gorm.DB
 	FindByIDWithTx(id uint, tx *gorm.DB) (*models.Task, error)
+	ReplaceTagsWithTx(task *models.Task, names []string, tx *gorm.DB) error
+	// ReplaceCoverVariantsWithTx mengganti seluruh varian cover task;
+	// variants kosong menghapus semuanya.
+	ReplaceCoverVariantsWithTx(task *models.Task, variants []models.CoverVariant, tx *gorm.DB) error
+	// HasCoverKey melaporkan apakah key storage dipakai sebagai cover atau
+	// varian cover sebuah task, termasuk task di trash. Path tersimpan
+	// dicocokkan lewat nama filenya (storage.KeyFromURL), sehingga cover
+	// lama dengan prefix URL lain tetap dikenali.
+	HasCoverKey(key string) (bool, error)
+	FindTrash() ([]models.Task, error)
+	FindTrashedByID(id uint) (*models.Task, error)
+	FindTrashedBefore(cutoff time.Time) ([]models.Task, error)
+	RestoreWithTx(id uint, tx *gorm.DB) error
+	PurgeWithTx(id uint, tx *gorm.DB) error
+	// WithOwner mengembalikan repository yang hanya melihat dan mengubah
+	// task milik ownerID. Task yang dibuat lewat repository itu otomatis
+	// dimiliki ownerID.
+	WithOwner(ownerID uint) TaskRepository
 }
 
 type TaskRepositoryImpl struct {
 	db *gorm.DB
+	// ownerID 0 berarti tidak dibatasi, dipakai oleh job sistem seperti
+	// pembersih trash.
+	ownerID uint
 }
 
 func NewTaskRepository(db *gorm.DB) TaskRepository {
@@ -28,37 +56,247 @@ func (r *TaskRepositoryImpl) GetDB() *gorm.DB {
 	return r.db
 }
 
+func (t *TaskRepositoryImpl) WithOwner(ownerID uint) TaskRepository {
+	return &TaskRepositoryImpl{db: t.db, ownerID: ownerID}
+}
+
+// owned membatasi query pada task milik ownerID jika repository dibatasi.
+func (t *TaskRepositoryImpl) owned(db *gorm.DB) *gorm.DB {
+	if t.ownerID == 0 {
+		return db
+	}
+	return db.Where("tasks.owner_id = ?", t.ownerID)
+}
+
 func (t *TaskRepositoryImpl) Create(task *models.Task) (*models.Task, error) {
+	if t.ownerID != 0 {
+		task.OwnerID = t.ownerID
+	}
 	err := t.db.Create(task).Error
 	return task, err
 }
 
+func (t *TaskRepositoryImpl) CreateWithTx(task *models.Task, tx *gorm.DB) error {
+	if t.ownerID != 0 {
+		task.OwnerID = t.ownerID
+	}
+	return tx.Create(task).Error
+}
+
 func (t *TaskRepositoryImpl) FindByID(id uint) (*models.Task, error) {
 	var task models.Task
-	err := t.db.First(&task, id).Error
+	err := t.owned(t.db).Preload("Tags").Preload("CoverVariants").First(&task, id).Error
 	return &task, err
 }
 
 func (t *TaskRepositoryImpl) FindAll() ([]models.Task, error) {
 	var task []models.Task
-	err := t.db.Find(&task).Error
+	err := t.owned(t.db).Preload("Tags").Preload("CoverVariants").Find(&task).Error
 	return task, err
 }
 
+// likeEscaper membuat kata pencarian dicocokkan apa adanya di LIKE, termasuk
+// % dan _.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// FindByQuery mengasumsikan query sudah dinormalisasi oleh service
+// (SortBy valid, Limit > 0).
+func (t *TaskRepositoryImpl) FindByQuery(query models.TaskQuery) (*models.TaskPage, error) {
+	offset, err := models.DecodeTaskCursor(query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	db := t.owned(t.db.Model(&models.Task{}))
+	if query.Status != "" {
+		db = db.Where("status = ?", query.Status)
+	}
+	if query.Tipe != "" {
+		db = db.Where("tipe = ?", query.Tipe)
+	}
+	for _, tag := range query.Tags {
+		db = db.Where("id IN (?)", t.db.Table("task_tags").
+			Select("task_tags.task_id").
+			Joins("JOIN tags ON tags.id = task_tags.tag_id").
+			Where("tags.name = ?", tag))
+	}
+	if query.Search != "" {
+		// Karakter escape dikirim sebagai parameter karena literal '\'
+		// tidak valid di MySQL dengan sql_mode bawaan.
+		pattern := "%" + likeEscaper.Replace(strings.ToLower(query.Search)) + "%"
+		db = db.Where(`LOWER(judul) LIKE ? ESCAPE ? OR LOWER(catatan) LIKE ? ESCAPE ?`, pattern, `\`, pattern, `\`)
+	}
+	if query.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *query.CreatedFrom)
+	}
+	if query.CreatedTo != nil {
+		db = db.Where("created_at <= ?", *query.CreatedTo)
+	}
+	if query.UpdatedFrom != nil {
+		db = db.Where("updated_at >= ?", *query.UpdatedFrom)
+	}
+	if query.UpdatedTo != nil {
+		db = db.Where("updated_at <= ?", *query.UpdatedTo)
+	}
+
+	page := &models.TaskPage{}
+	if err := db.Count(&page.Total).Error; err != nil {
+		return nil, err
+	}
+
+	order := models.TaskSortFields[query.SortBy] + " " + query.SortDir + ", id " + query.SortDir
+	// Ambil satu baris lebih untuk mengetahui apakah masih ada halaman berikutnya.
+	if err := db.Preload("Tags").Preload("CoverVariants").Order(order).Limit(query.Limit + 1).Offset(offset).Find(&page.Tasks).Error; err != nil {
+		return nil, err
+	}
+	if len(page.Tasks) > query.Limit {
+		page.Tasks = page.Tasks[:query.Limit]
+		page.NextCursor = models.EncodeTaskCursor(offset + query.Limit)
+	}
+	return page, nil
+}
+
 func (t *TaskRepositoryImpl) Update(task *models.Task) (*models.Task, error) {
+	if t.ownerID != 0 && task.OwnerID != t.ownerID {
+		return nil, gorm.ErrRecordNotFound
+	}
 	err := t.db.Save(task).Error
 	return task, err
 }
 
 func (t *TaskRepositoryImpl) Delete(id uint) error {
-	err := t.db.Delete(&models.Task{}, id).Error
+	err := t.owned(t.db).Delete(&models.Task{}, id).Error
 	return err
 }
 
+func (t *TaskRepositoryImpl) DeleteWithTx(id uint, tx *gorm.DB) error {
+	return t.owned(tx).Delete(&models.Task{}, id).Error
+}
+
+// FindTrash mengembalikan task yang sudah dihapus, yang terakhir dihapus lebih dulu.
+func (t *TaskRepositoryImpl) FindTrash() ([]models.Task, error) {
+	var tasks []models.Task
+	err := t.owned(t.db.Unscoped()).Preload("Tags").Preload("CoverVariants").
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC, id DESC").
+		Find(&tasks).Error
+	return tasks, err
+}
+
+func (t *TaskRepositoryImpl) FindTrashedByID(id uint) (*models.Task, error) {
+	var task models.Task
+	err := t.owned(t.db.Unscoped()).Preload("Tags").Preload("CoverVariants").Where("deleted_at IS NOT NULL").First(&task, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindTrashedBefore mengembalikan task yang sudah berada di trash sejak
+// sebelum cutoff.
+func (t *TaskRepositoryImpl) FindTrashedBefore(cutoff time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := t.owned(t.db.Unscoped()).Preload("CoverVariants").Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Find(&tasks).Error
+	return tasks, err
+}
+
+func (t *TaskRepositoryImpl) RestoreWithTx(id uint, tx *gorm.DB) error {
+	return t.owned(tx.Unscoped().Model(&models.Task{})).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		UpdateColumn("deleted_at", nil).Error
+}
+
+// PurgeWithTx menghapus task secara permanen beserta relasi tag-nya. Riwayat
+// task sengaja tidak ikut dihapus.
+func (t *TaskRepositoryImpl) PurgeWithTx(id uint, tx *gorm.DB) error {
+	if _, err := t.findUnscopedWithTx(id, tx); err != nil {
+		return err
+	}
+	if err := tx.Exec("DELETE FROM task_tags WHERE task_id = ?", id).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("task_id = ?", id).Delete(&models.CoverVariant{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Delete(&models.Task{}, id).Error
+}
+
+// findUnscopedWithTx mencari task aktif maupun yang ada di trash, dengan
+// tetap memperhatikan pemilik.
+func (t *TaskRepositoryImpl) findUnscopedWithTx(id uint, tx *gorm.DB) (*models.Task, error) {
+	var task models.Task
+	if err := t.owned(tx.Unscoped()).First(&task, id).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// FindEvents mengembalikan riwayat task, yang terbaru lebih dulu. Untuk
+// repository yang dibatasi pemilik, riwayat task milik orang lain kosong.
+func (t *TaskRepositoryImpl) FindEvents(taskID uint) ([]models.TaskEvent, error) {
+	var events []models.TaskEvent
+	db := t.db.Where("task_id = ?", taskID)
+	if t.ownerID != 0 {
+		db = db.Where("task_id IN (?)", t.db.Unscoped().Model(&models.Task{}).Select("id").Where("owner_id = ?", t.ownerID))
+	}
+	err := db.Order("created_at DESC, id DESC").Find(&events).Error
+	return events, err
+}
+
 func (t *TaskRepositoryImpl) FindByIDWithTx(id uint, tx *gorm.DB) (*models.Task, error) {
 	var task models.Task
-	if err := tx.First(&task, id).Error; err != nil {
+	if err := t.owned(tx).Preload("Tags").Preload("CoverVariants").First(&task, id).Error; err != nil {
 		return nil, err
 	}
 	return &task, nil
 }
+
+func (t *TaskRepositoryImpl) HasCoverKey(key string) (bool, error) {
+	var count int64
+	suffix := "%/" + likeEscaper.Replace(key)
+	variants := t.db.Model(&models.CoverVariant{}).Select("task_id").
+		Where("path = ? OR path LIKE ? ESCAPE ?", key, suffix, `\`)
+	err := t.owned(t.db.Unscoped().Model(&models.Task{})).
+		Where("cover = ? OR cover LIKE ? ESCAPE ? OR id IN (?)", key, suffix, `\`, variants).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (t *TaskRepositoryImpl) ReplaceCoverVariantsWithTx(task *models.Task, variants []models.CoverVariant, tx *gorm.DB) error {
+	if err := tx.Where("task_id = ?", task.ID).Delete(&models.CoverVariant{}).Error; err != nil {
+		return err
+	}
+	for i := range variants {
+		variants[i].ID = 0
+		variants[i].TaskID = task.ID
+	}
+	if len(variants) > 0 {
+		if err := tx.Create(&variants).Error; err != nil {
+			return err
+		}
+	}
+	task.CoverVariants = variants
+	return nil
+}
+
+// ReplaceTagsWithTx mengganti seluruh tag milik task dengan tag bernama names.
+// Tag milik pemilik task yang belum ada akan dibuat; names diasumsikan sudah
+// dinormalisasi.
+func (t *TaskRepositoryImpl) ReplaceTagsWithTx(task *models.Task, names []string, tx *gorm.DB) error {
+	tags, err := FindOrCreateTags(tx, task.OwnerID, names)
+	if err != nil {
+		return err
+	}
+	// Tulis langsung ke tabel join; Association().Replace ikut mengubah
+	// updated_at milik task.
+	if err := tx.Exec("DELETE FROM task_tags WHERE task_id = ?", task.ID).Error; err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if err := tx.Exec("INSERT INTO task_tags (task_id, tag_id) VALUES (?, ?)", task.ID, tag.ID).Error; err != nil {
+			return err
+		}
+	}
+	task.Tags = tags
+	return nil
+}
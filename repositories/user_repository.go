@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+)
+
+type UserRepository interface {
+	GetDB() *gorm.DB
+	CreateWithTx(user *models.User, tx *gorm.DB) error
+	FindByID(id uint) (*models.User, error)
+	FindByUsername(username string) (*models.User, error)
+	Count() (int64, error)
+	// AdoptOrphanTasksWithTx memberikan semua task tanpa pemilik, termasuk
+	// yang ada di trash, beserta tag tanpa pemilik kepada userID. Hanya
+	// dipanggil untuk pengguna baru yang belum punya tag.
+	AdoptOrphanTasksWithTx(userID uint, tx *gorm.DB) (int64, error)
+	CreateSession(session *models.Session) error
+	FindSession(id string) (*models.Session, error)
+	DeleteSession(id string) error
+	DeleteExpiredSessions(now time.Time) error
+}
+
+type UserRepositoryImpl struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &UserRepositoryImpl{db: db}
+}
+
+func (r *UserRepositoryImpl) GetDB() *gorm.DB {
+	return r.db
+}
+
+func (r *UserRepositoryImpl) CreateWithTx(user *models.User, tx *gorm.DB) error {
+	return tx.Create(user).Error
+}
+
+func (r *UserRepositoryImpl) FindByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepositoryImpl) FindByUsername(username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepositoryImpl) Count() (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Count(&count).Error
+	return count, err
+}
+
+func (r *UserRepositoryImpl) AdoptOrphanTasksWithTx(userID uint, tx *gorm.DB) (int64, error) {
+	result := tx.Unscoped().Model(&models.Task{}).
+		Where("owner_id = 0").
+		UpdateColumn("owner_id", userID)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if err := tx.Model(&models.Tag{}).Where("owner_id = 0").UpdateColumn("owner_id", userID).Error; err != nil {
+		return 0, err
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *UserRepositoryImpl) CreateSession(session *models.Session) error {
+	return r.db.Omit("User").Create(session).Error
+}
+
+// FindSession mengembalikan sesi beserta penggunanya.
+func (r *UserRepositoryImpl) FindSession(id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.Preload("User").Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *UserRepositoryImpl) DeleteSession(id string) error {
+	return r.db.Where("id = ?", id).Delete(&models.Session{}).Error
+}
+
+func (r *UserRepositoryImpl) DeleteExpiredSessions(now time.Time) error {
+	return r.db.Where("expires_at <= ?", now).Delete(&models.Session{}).Error
+}
@@ -10,28 +10,65 @@ import (
 	"github.com/nabilulilalbab/welcomesite/controllers"
 )
 
-func NewRouter(taskController *controllers.CarController, staticFS http.FileSystem) *httprouter.Router {
+// NewRouter menyusun semua route. Selain /login dan aset statis, setiap
+// route hanya bisa diakses pengguna yang sudah login, dan setiap request
+// yang mengubah data harus membawa token CSRF sesinya.
+func NewRouter(taskController *controllers.CarController, taskAPIController *controllers.TaskAPIController, tagAPIController *controllers.TagAPIController, authController *controllers.AuthController, staticFS http.FileSystem) http.Handler {
 	router := httprouter.New()
 
 	// Handler kustom untuk menyajikan file statis.
-	// Ini akan menyajikan dari sistem file fisik untuk /static/uploads/*
-	// dan dari embed.FS untuk semua path /static/* lainnya.
+	// Cover task (/static/uploads/tasks/*) disajikan dari storage cover
+	// (direktori upload atau bucket S3) hanya untuk pemilik task-nya, upload
+	// lain dari sistem file fisik di static/, dan semua path /static/*
+	// lainnya dari embed.FS.
 	fileHandler := http.StripPrefix("/static/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if strings.HasPrefix(r.URL.Path, "uploads/") {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "uploads/tasks/"):
+			taskController.ServeCover(w, r, strings.TrimPrefix(r.URL.Path, "uploads/tasks/"))
+		case strings.HasPrefix(r.URL.Path, "uploads/"):
 			http.ServeFile(w, r, filepath.Join("static", r.URL.Path))
-		} else {
+		default:
 			http.FileServer(staticFS).ServeHTTP(w, r)
 		}
 	}))
 	router.Handler(http.MethodGet, "/static/*filepath", fileHandler)
 
+	router.GET("/login", authController.LoginPage)
+	router.POST("/login", authController.Login)
+	router.POST("/logout", authController.Logout)
+
 	router.GET("/", taskController.ListTask)
 	router.POST("/task/add", taskController.ProcessAddTask)
 	router.POST("/task/update/:id", taskController.ProcessUpdateTask)
 	router.POST("/task/delete/:id", taskController.DeleteTask)
+	router.POST("/task/cover/delete/:id", taskController.RemoveCover)
+	router.GET("/task/card/:id", taskController.TaskCard)
+	router.GET("/trash", taskController.ListTrash)
+	router.POST("/trash/:id/restore", taskController.RestoreTask)
+	router.POST("/trash/:id/purge", taskController.PurgeTask)
+
+	// REST API JSON
+	router.GET("/api/v1/tasks", taskAPIController.ListTasks)
+	router.POST("/api/v1/tasks", taskAPIController.CreateTask)
+	router.GET("/api/v1/tasks/:id", taskAPIController.GetTask)
+	router.PUT("/api/v1/tasks/:id", taskAPIController.UpdateTask)
+	router.PATCH("/api/v1/tasks/:id", taskAPIController.PatchTask)
+	router.DELETE("/api/v1/tasks/:id", taskAPIController.DeleteTask)
+	router.DELETE("/api/v1/tasks/:id/cover", taskAPIController.RemoveCover)
+	router.GET("/api/v1/tasks/:id/events", taskAPIController.ListTaskEvents)
+	router.GET("/api/v1/tasks/:id/git", taskAPIController.GetGitStatus)
+	router.GET("/api/v1/trash", taskAPIController.ListTrash)
+	router.POST("/api/v1/trash/:id/restore", taskAPIController.RestoreTask)
+	router.DELETE("/api/v1/trash/:id", taskAPIController.PurgeTask)
+	router.GET("/api/v1/workflow", taskAPIController.GetWorkflow)
+	router.GET("/api/v1/tags", tagAPIController.ListTags)
+	router.POST("/api/v1/tags", tagAPIController.CreateTag)
+	router.PUT("/api/v1/tags/:id", tagAPIController.RenameTag)
+	router.DELETE("/api/v1/tags/:id", tagAPIController.DeleteTag)
+	router.POST("/api/v1/tags/:id/merge", tagAPIController.MergeTags)
 
 	// Tambahkan route untuk WebSocket
 	router.GET("/ws", taskController.HandleWebSocket)
 
-	return router
+	return authController.RequireUser(authController.RequireCSRF(router, taskController.MaxUploadBytes()))
 }
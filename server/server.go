@@ -0,0 +1,79 @@
+// Package server menjalankan HTTP server aplikasi dan mematikannya dengan
+// rapi: request yang sedang berjalan ditunggu selesai sebelum resource lain
+// (WebSocket, database) ditutup.
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/nabilulilalbab/welcomesite/config"
+)
+
+// New membuat http.Server dengan timeout dari konfigurasi.
+func New(cfg config.ServerConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: min(cfg.ReadTimeout, 10*time.Second),
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
+
+// Drainer menutup resource yang tidak ditangani http.Server.Shutdown,
+// misalnya koneksi WebSocket. Drainer harus berhenti menunggu ketika ctx
+// habis.
+type Drainer struct {
+	Name  string
+	Drain func(ctx context.Context) error
+}
+
+// Serve melayani request dari ln sampai ctx dibatalkan (biasanya oleh
+// SIGINT/SIGTERM), lalu berhenti menerima koneksi baru, menunggu request
+// yang berjalan, dan menjalankan drainers secara berurutan. Semua langkah
+// itu dibatasi shutdownTimeout.
+func Serve(ctx context.Context, srv *http.Server, ln net.Listener, shutdownTimeout time.Duration, drainers ...Drainer) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	select {
+	case err := <-serveErr:
+		// Server berhenti sendiri sebelum diminta, misalnya listener gagal.
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Mematikan server, menunggu request berjalan selesai (maks %s)...", shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		errs = append(errs, err)
+		log.Printf("Server tidak berhenti dengan rapi: %v", err)
+		srv.Close()
+	} else {
+		log.Println("Semua request HTTP selesai")
+	}
+	if err := <-serveErr; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		errs = append(errs, err)
+	}
+
+	for _, d := range drainers {
+		if err := d.Drain(shutdownCtx); err != nil {
+			errs = append(errs, err)
+			log.Printf("Gagal menutup %s: %v", d.Name, err)
+			continue
+		}
+		log.Printf("%s ditutup", d.Name)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+)
+
+type (
+	actorKey struct{}
+	userKey  struct{}
+)
+
+// SystemActor dipakai sebagai pelaku perubahan yang tidak berasal dari request
+// pengguna, misalnya job di background.
+const SystemActor = "system"
+
+// WithActor menyimpan nama pelaku perubahan di context; TaskService
+// mencatatnya di riwayat task.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return SystemActor
+}
+
+// WithUser menyimpan pengguna yang sedang login di context. TaskService
+// membatasi semua operasi pada task milik pengguna ini.
+func WithUser(ctx context.Context, user *models.User) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFromContext mengembalikan pengguna yang sedang login. Context tanpa
+// pengguna (job sistem, CLI) tidak dibatasi pemilik.
+func UserFromContext(ctx context.Context) (*models.User, bool) {
+	user, ok := ctx.Value(userKey{}).(*models.User)
+	return user, ok && user != nil
+}
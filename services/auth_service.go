@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+)
+
+var (
+	// ErrInvalidCredentials dikembalikan Login tanpa membedakan username yang
+	// tidak ada dan password yang salah.
+	ErrInvalidCredentials = errors.New("username atau password salah")
+	// ErrUnauthenticated dikembalikan ketika token sesi kosong, tidak dikenal
+	// atau sudah kedaluwarsa.
+	ErrUnauthenticated = errors.New("sesi tidak valid, silakan login")
+	ErrUsernameTaken   = errors.New("username sudah dipakai")
+	ErrInvalidUsername = errors.New("username tidak valid")
+	ErrWeakPassword    = errors.New("password terlalu lemah")
+)
+
+// DefaultSessionTTL dipakai jika WithSessionTTL tidak diberikan.
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+// maxPasswordBytes adalah batas input bcrypt.
+const maxPasswordBytes = 72
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+type AuthService interface {
+	// Register membuat pengguna baru. Pengguna pertama mengadopsi semua task
+	// yang dibuat sebelum ada akun.
+	Register(ctx context.Context, username, password string) (*models.User, error)
+	// Login memeriksa password dan membuat sesi baru. Token yang dikembalikan
+	// hanya disimpan di cookie; database menyimpan hash-nya.
+	Login(ctx context.Context, username, password string) (string, *models.Session, error)
+	Authenticate(ctx context.Context, token string) (*models.User, error)
+	Logout(ctx context.Context, token string) error
+	HasUsers(ctx context.Context) (bool, error)
+	// CSRFToken menurunkan token CSRF dari token sesi. Token ini aman ditulis
+	// ke halaman karena token sesi tidak bisa dihitung balik darinya.
+	CSRFToken(sessionToken string) string
+}
+
+type authServiceImpl struct {
+	repo       repositories.UserRepository
+	sessionTTL time.Duration
+	// dummyHash dibandingkan ketika username tidak ada supaya waktu respons
+	// tidak membocorkan username mana yang terdaftar.
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+}
+
+// AuthServiceOption mengatur dependensi opsional AuthService.
+type AuthServiceOption func(*authServiceImpl)
+
+// WithSessionTTL mengganti DefaultSessionTTL.
+func WithSessionTTL(ttl time.Duration) AuthServiceOption {
+	return func(s *authServiceImpl) {
+		s.sessionTTL = ttl
+	}
+}
+
+func NewAuthService(repository repositories.UserRepository, opts ...AuthServiceOption) AuthService {
+	s := &authServiceImpl{repo: repository, sessionTTL: DefaultSessionTTL}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *authServiceImpl) Register(ctx context.Context, username, password string) (*models.User, error) {
+	username = strings.TrimSpace(username)
+	if err := checkCredentials(username, password); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.FindByUsername(username); err == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUsernameTaken, username)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{Username: username, PasswordHash: string(hash)}
+	err = s.repo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.User{}).Count(&existing).Error; err != nil {
+			return err
+		}
+		if err := s.repo.CreateWithTx(user, tx); err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+		adopted, err := s.repo.AdoptOrphanTasksWithTx(user.ID, tx)
+		if err != nil {
+			return err
+		}
+		if adopted > 0 {
+			log.Printf("%d task lama sekarang dimiliki %s", adopted, user.Username)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *authServiceImpl) Login(ctx context.Context, username, password string) (string, *models.Session, error) {
+	user, err := s.repo.FindByUsername(strings.TrimSpace(username))
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil, err
+		}
+		s.dummyHashOnce.Do(func() {
+			s.dummyHash, _ = bcrypt.GenerateFromPassword([]byte("tidak-dipakai"), bcrypt.DefaultCost)
+		})
+		bcrypt.CompareHashAndPassword(s.dummyHash, []byte(password))
+		return "", nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return "", nil, ErrInvalidCredentials
+	}
+
+	now := time.Now()
+	if err := s.repo.DeleteExpiredSessions(now); err != nil {
+		log.Printf("Gagal menghapus sesi kedaluwarsa: %v", err)
+	}
+	token, err := newSessionToken()
+	if err != nil {
+		return "", nil, err
+	}
+	session := &models.Session{
+		ID:        hashSessionToken(token),
+		UserID:    user.ID,
+		User:      *user,
+		ExpiresAt: now.Add(s.sessionTTL),
+	}
+	if err := s.repo.CreateSession(session); err != nil {
+		return "", nil, err
+	}
+	return token, session, nil
+}
+
+func (s *authServiceImpl) Authenticate(ctx context.Context, token string) (*models.User, error) {
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	id := hashSessionToken(token)
+	session, err := s.repo.FindSession(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUnauthenticated
+		}
+		return nil, err
+	}
+	if session.Expired(time.Now()) {
+		if err := s.repo.DeleteSession(id); err != nil {
+			log.Printf("Gagal menghapus sesi kedaluwarsa: %v", err)
+		}
+		return nil, ErrUnauthenticated
+	}
+	return &session.User, nil
+}
+
+func (s *authServiceImpl) Logout(ctx context.Context, token string) error {
+	if token == "" {
+		return nil
+	}
+	return s.repo.DeleteSession(hashSessionToken(token))
+}
+
+func (s *authServiceImpl) HasUsers(ctx context.Context) (bool, error) {
+	count, err := s.repo.Count()
+	return count > 0, err
+}
+
+func (s *authServiceImpl) CSRFToken(sessionToken string) string {
+	mac := hmac.New(sha256.New, []byte(sessionToken))
+	mac.Write([]byte("csrf"))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func checkCredentials(username, password string) error {
+	switch {
+	case len(username) < models.MinUsernameLength || len(username) > models.MaxUsernameLength:
+		return fmt.Errorf("%w: panjang harus %d-%d karakter", ErrInvalidUsername, models.MinUsernameLength, models.MaxUsernameLength)
+	case !usernamePattern.MatchString(username):
+		return fmt.Errorf("%w: hanya huruf, angka, titik, garis bawah dan strip", ErrInvalidUsername)
+	case len(password) < models.MinPasswordLength:
+		return fmt.Errorf("%w: minimal %d karakter", ErrWeakPassword, models.MinPasswordLength)
+	case len(password) > maxPasswordBytes:
+		return fmt.Errorf("%w: maksimal %d byte", ErrWeakPassword, maxPasswordBytes)
+	}
+	return nil
+}
+
+func newSessionToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
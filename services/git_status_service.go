@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/utils"
+)
+
+// GitStatusReader membaca status git sebuah direktori; bawaannya
+// utils.ReadGitStatus.
+type GitStatusReader func(ctx context.Context, dir string) (*models.GitStatus, error)
+
+type GitStatusService interface {
+	// Status mengembalikan status git PathProject task. Hasil dari cache
+	// dipakai selama umurnya belum melewati TTL; selebihnya git dijalankan
+	// dan request lain untuk path yang sama menunggu hasil yang sama.
+	Status(ctx context.Context, taskID uint) (*models.GitStatus, error)
+	// Refresh menjalankan git ulang untuk setiap path yang dibaca dalam
+	// batas idle, dan membuang path lain dari cache.
+	Refresh(ctx context.Context)
+}
+
+// Nilai bawaan GitStatusService.
+const (
+	DefaultGitStatusTTL     = time.Minute
+	DefaultGitStatusIdle    = 10 * time.Minute
+	DefaultGitStatusTimeout = 10 * time.Second
+)
+
+type gitStatusServiceImpl struct {
+	tasks   TaskService
+	read    GitStatusReader
+	ttl     time.Duration
+	idle    time.Duration
+	timeout time.Duration
+
+	mu    sync.Mutex
+	cache map[string]*gitStatusEntry
+}
+
+type gitStatusEntry struct {
+	status    *models.GitStatus
+	err       error
+	fetchedAt time.Time
+	lastRead  time.Time
+	// loading terisi selama git berjalan untuk path ini dan ditutup ketika
+	// hasilnya sudah disimpan.
+	loading chan struct{}
+}
+
+// GitStatusOption mengatur GitStatusService.
+type GitStatusOption func(*gitStatusServiceImpl)
+
+// WithGitStatusTTL mengganti DefaultGitStatusTTL, umur maksimum status di
+// cache sebelum Status menjalankan git lagi.
+func WithGitStatusTTL(ttl time.Duration) GitStatusOption {
+	return func(s *gitStatusServiceImpl) {
+		s.ttl = ttl
+	}
+}
+
+// WithGitStatusIdle mengganti DefaultGitStatusIdle, lama path tidak dibaca
+// sebelum Refresh berhenti memperbaruinya.
+func WithGitStatusIdle(idle time.Duration) GitStatusOption {
+	return func(s *gitStatusServiceImpl) {
+		s.idle = idle
+	}
+}
+
+// WithGitStatusReader mengganti utils.ReadGitStatus, misalnya di test.
+func WithGitStatusReader(read GitStatusReader) GitStatusOption {
+	return func(s *gitStatusServiceImpl) {
+		s.read = read
+	}
+}
+
+// NewGitStatusService membaca path proyek lewat tasks sehingga hanya task
+// milik pengguna yang login yang bisa diperiksa.
+func NewGitStatusService(tasks TaskService, opts ...GitStatusOption) GitStatusService {
+	s := &gitStatusServiceImpl{
+		tasks:   tasks,
+		read:    utils.ReadGitStatus,
+		ttl:     DefaultGitStatusTTL,
+		idle:    DefaultGitStatusIdle,
+		timeout: DefaultGitStatusTimeout,
+		cache:   make(map[string]*gitStatusEntry),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *gitStatusServiceImpl) Status(ctx context.Context, taskID uint) (*models.GitStatus, error) {
+	dir, err := s.tasks.ProjectDir(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	e := s.entry(dir)
+	e.lastRead = time.Now()
+	fresh := !e.fetchedAt.IsZero() && time.Since(e.fetchedAt) < s.ttl
+	s.mu.Unlock()
+	if !fresh {
+		select {
+		case <-s.load(dir, e):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.err != nil {
+		return nil, e.err
+	}
+	status := *e.status
+	return &status, nil
+}
+
+func (s *gitStatusServiceImpl) Refresh(ctx context.Context) {
+	s.mu.Lock()
+	active := make(map[string]*gitStatusEntry)
+	for dir, e := range s.cache {
+		if time.Since(e.lastRead) > s.idle {
+			if e.loading == nil {
+				delete(s.cache, dir)
+			}
+			continue
+		}
+		active[dir] = e
+	}
+	s.mu.Unlock()
+
+	for dir, e := range active {
+		if ctx.Err() != nil {
+			return
+		}
+		<-s.load(dir, e)
+	}
+}
+
+// entry harus dipanggil dengan mu terkunci.
+func (s *gitStatusServiceImpl) entry(dir string) *gitStatusEntry {
+	e, ok := s.cache[dir]
+	if !ok {
+		e = &gitStatusEntry{}
+		s.cache[dir] = e
+	}
+	return e
+}
+
+// load menjalankan git untuk dir di goroutine terpisah dan menyimpan
+// hasilnya di e, kecuali git sedang berjalan untuk e. Channel yang
+// dikembalikan ditutup ketika hasilnya tersimpan, sehingga pemanggil bisa
+// berhenti menunggu ketika request-nya dibatalkan. Git dijalankan dengan
+// context sendiri supaya request yang dibatalkan tidak menyimpan error di
+// cache.
+func (s *gitStatusServiceImpl) load(dir string, e *gitStatusEntry) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.loading != nil {
+		return e.loading
+	}
+	loading := make(chan struct{})
+	e.loading = loading
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		status, err := s.read(ctx, dir)
+		cancel()
+		if err != nil {
+			log.Printf("Gagal membaca status git %s: %v", dir, err)
+		}
+
+		s.mu.Lock()
+		e.status, e.err, e.fetchedAt, e.loading = status, err, time.Now(), nil
+		s.mu.Unlock()
+		close(loading)
+	}()
+	return loading
+}
+
+// RunGitStatusRefresher memanggil Refresh setiap interval sampai ctx
+// dibatalkan, sehingga status proyek yang sedang dilihat tetap baru tanpa
+// membuat request menunggu git.
+func RunGitStatusRefresher(ctx context.Context, service GitStatusService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			service.Refresh(ctx)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag tidak ditemukan")
+	// ErrTagExists dikembalikan saat membuat/rename tag ke nama yang sudah
+	// dipakai; gunakan MergeTags untuk menggabungkan keduanya.
+	ErrTagExists  = errors.New("tag dengan nama tersebut sudah ada")
+	ErrInvalidTag = errors.New("nama tag tidak valid")
+)
+
+// TagService hanya melihat dan mengubah tag milik pengguna di ctx. Setiap
+// perubahan tag mencatat TaskEvent pada task yang memakainya.
+type TagService interface {
+	GetAllTags(ctx context.Context) ([]models.Tag, error)
+	CreateTag(ctx context.Context, name string) (*models.Tag, error)
+	RenameTag(ctx context.Context, id uint, name string) (*models.Tag, error)
+	DeleteTag(ctx context.Context, id uint) error
+	MergeTags(ctx context.Context, sourceID, targetID uint) (*models.Tag, error)
+}
+
+type tagServiceImpl struct {
+	repo repositories.TagRepository
+}
+
+func NewTagService(repository repositories.TagRepository) TagService {
+	return &tagServiceImpl{repo: repository}
+}
+
+func (s *tagServiceImpl) GetAllTags(ctx context.Context) ([]models.Tag, error) {
+	return s.tags(ctx).FindAllWithCount()
+}
+
+func (s *tagServiceImpl) CreateTag(ctx context.Context, name string) (*models.Tag, error) {
+	tags := s.tags(ctx)
+	name, err := checkName(tags, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	return tags.Create(&models.Tag{Name: name})
+}
+
+func (s *tagServiceImpl) RenameTag(ctx context.Context, id uint, name string) (*models.Tag, error) {
+	tags := s.tags(ctx)
+	tag, err := findTag(tags, id)
+	if err != nil {
+		return nil, err
+	}
+	name, err = checkName(tags, name, id)
+	if err != nil {
+		return nil, err
+	}
+	tag.Name = name
+	err = s.changeTags(ctx, []uint{id}, func(tx *gorm.DB) error {
+		return tags.UpdateWithTx(tag, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *tagServiceImpl) DeleteTag(ctx context.Context, id uint) error {
+	tags := s.tags(ctx)
+	if _, err := findTag(tags, id); err != nil {
+		return err
+	}
+	return s.changeTags(ctx, []uint{id}, func(tx *gorm.DB) error {
+		return tags.DeleteWithTx(id, tx)
+	})
+}
+
+func (s *tagServiceImpl) MergeTags(ctx context.Context, sourceID, targetID uint) (*models.Tag, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: tidak bisa menggabungkan tag dengan dirinya sendiri", ErrInvalidTag)
+	}
+	tags := s.tags(ctx)
+	if _, err := findTag(tags, sourceID); err != nil {
+		return nil, err
+	}
+	target, err := findTag(tags, targetID)
+	if err != nil {
+		return nil, err
+	}
+	err = s.changeTags(ctx, []uint{sourceID, targetID}, func(tx *gorm.DB) error {
+		return tags.MergeWithTx(sourceID, targetID, tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// tags mengembalikan repository yang dibatasi pada tag milik pengguna di
+// ctx, atau repository tanpa batasan untuk pemanggil sistem.
+func (s *tagServiceImpl) tags(ctx context.Context) repositories.TagRepository {
+	if user, ok := UserFromContext(ctx); ok {
+		return s.repo.WithOwner(user.ID)
+	}
+	return s.repo
+}
+
+// changeTags menjalankan change di dalam transaksi lalu mencatat perubahan
+// field "tags" pada setiap task yang memakai tagIDs, termasuk task di trash.
+func (s *tagServiceImpl) changeTags(ctx context.Context, tagIDs []uint, change func(tx *gorm.DB) error) error {
+	return s.repo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		taskIDs, err := s.repo.FindTaskIDsWithTx(tagIDs, tx)
+		if err != nil {
+			return err
+		}
+		before, err := s.repo.TaskTagNamesWithTx(taskIDs, tx)
+		if err != nil {
+			return err
+		}
+		if err := change(tx); err != nil {
+			return err
+		}
+		after, err := s.repo.TaskTagNamesWithTx(taskIDs, tx)
+		if err != nil {
+			return err
+		}
+		for _, taskID := range taskIDs {
+			old, updated := strings.Join(before[taskID], ", "), strings.Join(after[taskID], ", ")
+			if old == updated {
+				continue
+			}
+			changes := models.FieldChanges{{Field: "tags", Old: old, New: updated}}
+			if err := recordEvent(ctx, tx, taskID, models.TaskEventUpdate, changes); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func findTag(tags repositories.TagRepository, id uint) (*models.Tag, error) {
+	tag, err := tags.FindByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("tag dengan ID %d: %w", id, ErrTagNotFound)
+	}
+	return tag, err
+}
+
+// checkName menormalisasi nama dan memastikan belum dipakai tag lain milik
+// pemilik yang sama selain selfID.
+func checkName(tags repositories.TagRepository, name string, selfID uint) (string, error) {
+	name = models.NormalizeTagName(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: nama tag wajib diisi", ErrInvalidTag)
+	}
+	if utf8.RuneCountInString(name) > models.MaxTagNameLength {
+		return "", fmt.Errorf("%w: maksimal %d karakter", ErrInvalidTag, models.MaxTagNameLength)
+	}
+	existing, err := tags.FindByName(name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+	if existing != nil && existing.ID != selfID {
+		return "", fmt.Errorf("%w: %q", ErrTagExists, name)
+	}
+	return name, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+)
+
+// Jenis perubahan yang dikirim ke TaskNotifier. Task yang dipulihkan dari
+// trash dikirim sebagai TaskCreated karena muncul kembali di daftar.
+const (
+	TaskCreated = "created"
+	TaskUpdated = "updated"
+	TaskDeleted = "deleted"
+)
+
+// TaskChange adalah satu perubahan task yang sudah di-commit.
+type TaskChange struct {
+	Action string `json:"action"`
+	TaskID uint   `json:"task_id"`
+	// OwnerID menentukan siapa yang boleh menerima perubahan ini.
+	OwnerID uint   `json:"-"`
+	Actor   string `json:"actor"`
+	// Task kosong untuk TaskDeleted.
+	Task *models.Task `json:"task,omitempty"`
+}
+
+// TaskNotifier menerima perubahan task setelah transaksinya berhasil.
+// TaskChanged dipanggil di goroutine pemanggil service sehingga tidak boleh
+// memblokir.
+type TaskNotifier interface {
+	TaskChanged(change TaskChange)
+}
+
+// WithNotifier memasang penerima perubahan task, misalnya hub WebSocket.
+func WithNotifier(notifier TaskNotifier) TaskServiceOption {
+	return func(s *taskServiceImpl) {
+		s.notifier = notifier
+	}
+}
+
+func (s *taskServiceImpl) notify(ctx context.Context, action string, task *models.Task) {
+	if s.notifier == nil {
+		return
+	}
+	change := TaskChange{Action: action, TaskID: task.ID, OwnerID: task.OwnerID, Actor: ActorFromContext(ctx)}
+	if action != TaskDeleted {
+		change.Task = task
+	}
+	s.notifier.TaskChanged(change)
+}
@@ -2,79 +2,243 @@ package services
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
 
 	"github.com/nabilulilalbab/welcomesite/models"
 	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/storage"
 	"github.com/nabilulilalbab/welcomesite/utils"
 )
 
+var (
+	// ErrTaskNotFound dikembalikan ketika task yang diminta tidak ada di database.
+	ErrTaskNotFound = errors.New("task tidak ditemukan")
+	// ErrInvalidQuery dikembalikan ketika parameter filter/sort/paginasi tidak valid.
+	ErrInvalidQuery = errors.New("query task tidak valid")
+	// ErrUnknownStatus dikembalikan ketika status tidak terdaftar di workflow.
+	ErrUnknownStatus = errors.New("status tidak dikenal")
+	// ErrIllegalTransition cocok (errors.Is) dengan setiap *TransitionError.
+	ErrIllegalTransition = errors.New("perpindahan status tidak diizinkan")
+	// ErrCoverTooLarge dikembalikan ketika file cover melebihi UploadOptions.MaxBytes.
+	ErrCoverTooLarge = errors.New("file cover terlalu besar")
+	// ErrNoProjectPath dikembalikan ProjectDir untuk task tanpa PathProject.
+	ErrNoProjectPath = errors.New("task tidak memiliki path proyek")
+	// ErrInvalidProjectPath dikembalikan ProjectDir ketika PathProject bukan
+	// direktori absolut yang ada.
+	ErrInvalidProjectPath = errors.New("path proyek tidak valid")
+	// ErrUnknownLauncher dikembalikan ketika task memilih profil launcher
+	// yang tidak terdaftar.
+	ErrUnknownLauncher = errors.New("launcher tidak dikenal")
+)
+
+// TransitionError dikembalikan UpdateTask ketika workflow tidak mengizinkan
+// perpindahan dari From ke To.
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("perpindahan status dari %q ke %q tidak diizinkan", e.From, e.To)
+}
+
+func (e *TransitionError) Is(target error) bool {
+	return target == ErrIllegalTransition
+}
+
 type TaskService interface {
-	CreateTask(task *models.Task, coverFile *multipart.FileHeader) (*models.Task, error)
-	GetTaskByID(id uint) (*models.Task, error)
-	GetAllTasks() ([]models.Task, error)
-	UpdateTask(id uint, task *models.Task, fileHeader *multipart.FileHeader) (*models.Task, error)
-	DeleteTask(id uint) error
+	CreateTask(ctx context.Context, task *models.Task, coverFile *multipart.FileHeader) (*models.Task, error)
+	GetTaskByID(ctx context.Context, id uint) (*models.Task, error)
+	GetAllTasks(ctx context.Context) ([]models.Task, error)
+	ListTasks(ctx context.Context, query models.TaskQuery) (*models.TaskPage, error)
+	GetTaskEvents(ctx context.Context, id uint) ([]models.TaskEvent, error)
+	// ProjectDir mengembalikan PathProject task setelah memastikan path itu
+	// direktori yang ada. Hanya path yang tersimpan di task yang boleh dibuka
+	// di terminal.
+	ProjectDir(ctx context.Context, id uint) (string, error)
+	Workflow() *models.Workflow
+	Uploads() UploadOptions
+	// Covers adalah storage tempat file cover disimpan.
+	Covers() storage.Storage
+	// OwnsCover melaporkan apakah key adalah file cover task yang boleh
+	// dilihat pengguna di ctx, termasuk task miliknya di trash.
+	OwnsCover(ctx context.Context, key string) (bool, error)
+	// Launchers adalah profil yang boleh dipilih task untuk membuka proyek.
+	Launchers() *utils.Launchers
+	// UpdateTask mengubah field task yang tidak kosong; lihat
+	// models.PatchFromTask.
+	UpdateTask(ctx context.Context, id uint, task *models.Task, fileHeader *multipart.FileHeader) (*models.Task, error)
+	// PatchTask mengubah, mengosongkan atau membiarkan setiap field sesuai
+	// patch.
+	PatchTask(ctx context.Context, id uint, patch *models.TaskPatch, fileHeader *multipart.FileHeader) (*models.Task, error)
+	// RemoveCover mengosongkan cover task dan menghapus filenya. Task tanpa
+	// cover dikembalikan apa adanya.
+	RemoveCover(ctx context.Context, id uint) (*models.Task, error)
+	DeleteTask(ctx context.Context, id uint) error
+	ListTrash(ctx context.Context) ([]models.Task, error)
+	RestoreTask(ctx context.Context, id uint) (*models.Task, error)
+	PurgeTask(ctx context.Context, id uint) error
+	PurgeTrashedBefore(ctx context.Context, cutoff time.Time) (int, error)
 }
 
 type taskServiceImpl struct {
-	repo        repositories.TaskRepository
-	uploadsPath string
+	repo      repositories.TaskRepository
+	covers    storage.Storage
+	workflow  *models.Workflow
+	uploads   UploadOptions
+	notifier  TaskNotifier
+	launchers *utils.Launchers
+}
+
+// UploadOptions mengatur batas dan ukuran cover yang disimpan.
+type UploadOptions struct {
+	// MaxBytes adalah ukuran maksimum file cover yang diterima.
+	MaxBytes int64
+	// CoverWidth adalah lebar maksimum cover setelah di-resize.
+	CoverWidth uint
+	// MaxDimension adalah lebar dan tinggi maksimum gambar asli, dibaca dari
+	// header sebelum gambar didecode. 0 berarti tidak dibatasi.
+	MaxDimension int
+	// MaxPixels adalah jumlah piksel maksimum gambar asli. 0 berarti tidak
+	// dibatasi.
+	MaxPixels int
+}
+
+// DefaultUploadOptions dipakai jika WithUploadOptions tidak diberikan.
+var DefaultUploadOptions = UploadOptions{MaxBytes: 10 << 20, CoverWidth: 800, MaxDimension: 10000, MaxPixels: 40_000_000}
+
+// TaskServiceOption mengatur dependensi opsional TaskService.
+type TaskServiceOption func(*taskServiceImpl)
+
+// WithWorkflow mengganti models.DefaultWorkflow dengan workflow yang sudah
+// divalidasi.
+func WithWorkflow(workflow *models.Workflow) TaskServiceOption {
+	return func(s *taskServiceImpl) {
+		s.workflow = workflow
+	}
+}
+
+// WithUploadOptions mengganti DefaultUploadOptions.
+func WithUploadOptions(uploads UploadOptions) TaskServiceOption {
+	return func(s *taskServiceImpl) {
+		s.uploads = uploads
+	}
+}
+
+// WithStorage menyimpan cover di covers, misalnya bucket S3, alih-alih di
+// direktori uploadsPath.
+func WithStorage(covers storage.Storage) TaskServiceOption {
+	return func(s *taskServiceImpl) {
+		s.covers = covers
+	}
 }
 
-func NewTaskService(repository repositories.TaskRepository, uploadsPath string) TaskService {
-	return &taskServiceImpl{repo: repository, uploadsPath: uploadsPath}
+// WithLaunchers mengganti profil bawaan utils.NewLaunchers(nil).
+func WithLaunchers(launchers *utils.Launchers) TaskServiceOption {
+	return func(s *taskServiceImpl) {
+		s.launchers = launchers
+	}
 }
 
-func (s *taskServiceImpl) CreateTask(task *models.Task, coverFile *multipart.FileHeader) (*models.Task, error) {
-	tx := s.repo.GetDB().Begin()
-	fmt.Println("tx : ", *tx)
+// NewTaskService menyimpan cover di direktori uploadsPath kecuali
+// WithStorage diberikan.
+func NewTaskService(repository repositories.TaskRepository, uploadsPath string, opts ...TaskServiceOption) TaskService {
+	s := &taskServiceImpl{
+		repo:      repository,
+		covers:    storage.NewLocal(uploadsPath, CoverURLPrefix),
+		workflow:  models.DefaultWorkflow(),
+		uploads:   DefaultUploadOptions,
+		launchers: utils.NewLaunchers(nil),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *taskServiceImpl) CreateTask(ctx context.Context, task *models.Task, coverFile *multipart.FileHeader) (*models.Task, error) {
+	tagNames, err := normalizeTaskTags(task.Tags)
+	if err != nil {
+		return nil, err
+	}
+	cover, err := s.checkCover(coverFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLauncher(task.Launcher); err != nil {
+		return nil, err
+	}
+	if task.Status == "" {
+		task.Status = s.workflow.Initial
+	} else if !s.workflow.IsValid(task.Status) {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStatus, task.Status)
+	}
+	task.Tags = nil
+	tx := s.repo.GetDB().WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
-	if err := tx.Create(task).Error; err != nil {
+	if err := s.tasks(ctx).CreateWithTx(task, tx); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
-	if coverFile != nil {
-		uniqueFileName := "task_" + strconv.FormatUint(uint64(task.ID), 10) + "_" + strconv.FormatInt(time.Now().UnixNano(), 10) + filepath.Ext(coverFile.Filename)
-		diskPath := filepath.Join(s.uploadsPath, uniqueFileName)
-		src, err := coverFile.Open()
-		if err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-		defer src.Close()
-		buf := new(bytes.Buffer)
-		if _, err := io.Copy(buf, src); err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-		err = utils.SaveResizedImage(bytes.NewReader(buf.Bytes()), filepath.Ext(coverFile.Filename), diskPath, 800)
-		if err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-		task.Cover = "/static/uploads/tasks/" + uniqueFileName
-		if err := tx.Model(task).Update("cover", task.Cover).Error; err != nil {
+	if err := s.tasks(ctx).ReplaceTagsWithTx(task, tagNames, tx); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	var written []string
+	if cover != nil {
+		if written, err = s.saveCover(ctx, tx, task, cover); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
 	}
+	changes := models.DiffTask(&models.Task{}, task)
+	if err := recordEvent(ctx, tx, task.ID, models.TaskEventCreate, changes); err != nil {
+		tx.Rollback()
+		s.removeCoverFiles(ctx, written)
+		return nil, err
+	}
 	if err := tx.Commit().Error; err != nil {
+		s.removeCoverFiles(ctx, written)
 		return nil, err
 	}
+	s.notify(ctx, TaskCreated, task)
 	return task, nil
 }
 
-func (s *taskServiceImpl) UpdateTask(id uint, taskInput *models.Task, coverFile *multipart.FileHeader) (*models.Task, error) {
-	tx := s.repo.GetDB().Begin()
+func (s *taskServiceImpl) UpdateTask(ctx context.Context, id uint, taskInput *models.Task, coverFile *multipart.FileHeader) (*models.Task, error) {
+	return s.PatchTask(ctx, id, models.PatchFromTask(taskInput), coverFile)
+}
+
+func (s *taskServiceImpl) PatchTask(ctx context.Context, id uint, patch *models.TaskPatch, coverFile *multipart.FileHeader) (*models.Task, error) {
+	tagNames, err := normalizeTaskTags(models.TagsFromNames(patch.Tags.Value))
+	if err != nil {
+		return nil, err
+	}
+	cover, err := s.checkCover(coverFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLauncher(patch.Launcher.Value); err != nil {
+		return nil, err
+	}
+	if patch.Status.Set && patch.Status.Value == "" {
+		return nil, fmt.Errorf("%w: status tidak boleh kosong", ErrUnknownStatus)
+	}
+	tx := s.repo.GetDB().WithContext(ctx).Begin()
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
@@ -83,83 +247,475 @@ func (s *taskServiceImpl) UpdateTask(id uint, taskInput *models.Task, coverFile
 			tx.Rollback()
 		}
 	}()
-	existingTask, err := s.repo.FindByIDWithTx(id, tx)
+	existingTask, err := s.tasks(ctx).FindByIDWithTx(id, tx)
 	if err != nil {
 		tx.Rollback()
-		return nil, fmt.Errorf("task with id %d not found", id)
+		return nil, wrapNotFound(id, err)
 	}
-	if err := tx.Model(existingTask).Updates(taskInput).Error; err != nil {
+	if err := s.checkTransition(existingTask.Status, patch.Status.Value); err != nil {
 		tx.Rollback()
 		return nil, err
 	}
-	if coverFile != nil {
-		if existingTask.Cover != "" {
-			oldPath := filepath.Join(".", existingTask.Cover)
-			if err := os.Remove(oldPath); err != nil {
-				fmt.Printf("Warning: could not remove old file %s: %v\n", oldPath, err)
-			}
-		}
-		uniqueFileName := "task_" + strconv.FormatUint(uint64(existingTask.ID), 10) + "_" + strconv.FormatInt(time.Now().UnixNano(), 10) + filepath.Ext(coverFile.Filename)
-		diskPath := filepath.Join(s.uploadsPath, uniqueFileName)
-		src, err := coverFile.Open()
-		if err != nil {
-			tx.Rollback()
-			return nil, err
-		}
-		defer src.Close()
-		buf := new(bytes.Buffer)
-		if _, err := io.Copy(buf, src); err != nil {
+	before := *existingTask
+	// Select membuat GORM ikut menulis value kosong dan NULL.
+	if columns := patch.Apply(existingTask); len(columns) > 0 {
+		if err := tx.Model(existingTask).Select(columns).Updates(existingTask).Error; err != nil {
 			tx.Rollback()
 			return nil, err
 		}
-		err = utils.SaveResizedImage(bytes.NewReader(buf.Bytes()), filepath.Ext(coverFile.Filename), diskPath, 800)
-		if err != nil {
+	}
+	if patch.Tags.Set {
+		if err := s.tasks(ctx).ReplaceTagsWithTx(existingTask, tagNames, tx); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
-		newCoverPath := "/static/uploads/tasks/" + uniqueFileName
-		if err := tx.Model(existingTask).Update("cover", newCoverPath).Error; err != nil {
+	}
+	// Cover lama baru dihapus setelah commit dan cover baru dihapus lagi
+	// jika transaksi batal, sehingga task tidak pernah menunjuk file yang
+	// sudah hilang dan tidak ada file yatim.
+	var written, replaced []string
+	if cover != nil {
+		replaced = existingTask.CoverPaths()
+		if written, err = s.saveCover(ctx, tx, existingTask, cover); err != nil {
 			tx.Rollback()
 			return nil, err
 		}
-		existingTask.Cover = newCoverPath
+	}
+	if err := recordUpdateEvents(ctx, tx, &before, existingTask); err != nil {
+		tx.Rollback()
+		s.removeCoverFiles(ctx, written)
+		return nil, err
 	}
 	if err := tx.Commit().Error; err != nil {
+		s.removeCoverFiles(ctx, written)
 		return nil, err
 	}
+	s.removeCoverFiles(ctx, replaced)
+	s.notify(ctx, TaskUpdated, existingTask)
 	return existingTask, nil
 }
 
-func (s *taskServiceImpl) GetTaskByID(id uint) (*models.Task, error) {
-	return s.repo.FindByID(id)
+func (s *taskServiceImpl) RemoveCover(ctx context.Context, id uint) (*models.Task, error) {
+	var task *models.Task
+	var oldPaths []string
+	err := s.repo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		task, err = s.tasks(ctx).FindByIDWithTx(id, tx)
+		if err != nil {
+			return wrapNotFound(id, err)
+		}
+		if task.Cover == "" {
+			return nil
+		}
+		oldCover := task.Cover
+		oldPaths = task.CoverPaths()
+		if err := tx.Model(task).Update("cover", "").Error; err != nil {
+			return err
+		}
+		if err := s.tasks(ctx).ReplaceCoverVariantsWithTx(task, nil, tx); err != nil {
+			return err
+		}
+		changes := models.FieldChanges{{Field: "cover", Old: oldCover, New: ""}}
+		return recordEvent(ctx, tx, task.ID, models.TaskEventCover, changes)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if oldPaths == nil {
+		return task, nil
+	}
+
+	// File dihapus setelah commit: jika transaksi gagal, cover lama masih
+	// dipakai dan filenya harus tetap ada.
+	s.removeCoverFiles(ctx, oldPaths)
+	s.notify(ctx, TaskUpdated, task)
+	return task, nil
+}
+
+func (s *taskServiceImpl) Workflow() *models.Workflow {
+	return s.workflow
+}
+
+func (s *taskServiceImpl) Uploads() UploadOptions {
+	return s.uploads
+}
+
+func (s *taskServiceImpl) Covers() storage.Storage {
+	return s.covers
 }
 
-func (s *taskServiceImpl) GetAllTasks() ([]models.Task, error) {
-	return s.repo.FindAll()
+func (s *taskServiceImpl) OwnsCover(ctx context.Context, key string) (bool, error) {
+	return s.tasks(ctx).HasCoverKey(key)
 }
 
-func (s *taskServiceImpl) DeleteTask(id uint) error {
-	// 1. Ambil data tugas untuk mendapatkan path cover
-	task, err := s.repo.FindByID(id)
+func (s *taskServiceImpl) Launchers() *utils.Launchers {
+	return s.launchers
+}
+
+func (s *taskServiceImpl) GetTaskByID(ctx context.Context, id uint) (*models.Task, error) {
+	task, err := s.tasks(ctx).FindByID(id)
 	if err != nil {
-		return fmt.Errorf("gagal menemukan tugas dengan ID %d: %w", id, err)
+		return nil, wrapNotFound(id, err)
 	}
+	return task, nil
+}
 
-	// 2. Hapus file cover jika ada
-	if task.Cover != "" {
-		// Path cover di database adalah URL, kita perlu mengubahnya menjadi path fisik
-		// Contoh: /static/uploads/tasks/task_1_...png -> static/uploads/tasks/task_1_...png
-		// Kita perlu menghapus '/static/' dari awal URL
-		fullPath := filepath.Join(s.uploadsPath, filepath.Base(task.Cover))
+func (s *taskServiceImpl) GetAllTasks(ctx context.Context) ([]models.Task, error) {
+	return s.tasks(ctx).FindAll()
+}
 
-		err := os.Remove(fullPath)
-		if err != nil {
-			// Log error tapi jangan kembalikan error, agar penghapusan database tetap berjalan
-			// karena penghapusan file mungkin gagal karena file tidak ada, izin, dll.
-			fmt.Printf("Peringatan: Gagal menghapus file cover %s: %v\n", fullPath, err)
+// GetTaskEvents mengembalikan riwayat perubahan task, yang terbaru lebih dulu.
+// Riwayat task yang berada di trash tetap bisa dilihat.
+func (s *taskServiceImpl) GetTaskEvents(ctx context.Context, id uint) ([]models.TaskEvent, error) {
+	if _, err := s.tasks(ctx).FindByID(id); err != nil {
+		if _, trashErr := s.tasks(ctx).FindTrashedByID(id); trashErr != nil {
+			return nil, wrapNotFound(id, err)
+		}
+	}
+	return s.tasks(ctx).FindEvents(id)
+}
+
+func (s *taskServiceImpl) ProjectDir(ctx context.Context, id uint) (string, error) {
+	task, err := s.GetTaskByID(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if task.PathProject == nil || strings.TrimSpace(*task.PathProject) == "" {
+		return "", fmt.Errorf("task dengan ID %d: %w", id, ErrNoProjectPath)
+	}
+	dir := filepath.Clean(*task.PathProject)
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("%w: %q bukan path absolut", ErrInvalidProjectPath, dir)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidProjectPath, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("%w: %q bukan direktori", ErrInvalidProjectPath, dir)
+	}
+	return dir, nil
+}
+
+func (s *taskServiceImpl) ListTasks(ctx context.Context, query models.TaskQuery) (*models.TaskPage, error) {
+	if query.SortBy == "" {
+		query.SortBy = "created_at"
+	}
+	if _, ok := models.TaskSortFields[query.SortBy]; !ok {
+		return nil, fmt.Errorf("%w: sort %q tidak dikenal", ErrInvalidQuery, query.SortBy)
+	}
+	switch strings.ToLower(query.SortDir) {
+	case "", "asc":
+		query.SortDir = "asc"
+	case "desc":
+		query.SortDir = "desc"
+	default:
+		return nil, fmt.Errorf("%w: arah sort %q tidak dikenal", ErrInvalidQuery, query.SortDir)
+	}
+	switch {
+	case query.Limit < 0:
+		return nil, fmt.Errorf("%w: limit tidak boleh negatif", ErrInvalidQuery)
+	case query.Limit == 0:
+		query.Limit = models.DefaultTaskQueryLimit
+	case query.Limit > models.MaxTaskQueryLimit:
+		query.Limit = models.MaxTaskQueryLimit
+	}
+	if _, err := models.DecodeTaskCursor(query.Cursor); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	query.Search = strings.TrimSpace(query.Search)
+	query.Tags = models.NormalizeTagNames(query.Tags)
+	return s.tasks(ctx).FindByQuery(query)
+}
+
+// DeleteTask memindahkan task ke trash. Cover tetap disimpan sampai task
+// di-purge supaya task bisa dipulihkan utuh.
+func (s *taskServiceImpl) DeleteTask(ctx context.Context, id uint) error {
+	task, err := s.tasks(ctx).FindByID(id)
+	if err != nil {
+		return wrapNotFound(id, err)
+	}
+	err = s.repo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := recordEvent(ctx, tx, id, models.TaskEventDelete, models.DiffTask(task, &models.Task{})); err != nil {
+			return err
+		}
+		return s.tasks(ctx).DeleteWithTx(id, tx)
+	})
+	if err != nil {
+		return err
+	}
+	s.notify(ctx, TaskDeleted, task)
+	return nil
+}
+
+func (s *taskServiceImpl) ListTrash(ctx context.Context) ([]models.Task, error) {
+	return s.tasks(ctx).FindTrash()
+}
+
+func (s *taskServiceImpl) RestoreTask(ctx context.Context, id uint) (*models.Task, error) {
+	task, err := s.tasks(ctx).FindTrashedByID(id)
+	if err != nil {
+		return nil, wrapNotFound(id, err)
+	}
+	err = s.repo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := s.tasks(ctx).RestoreWithTx(id, tx); err != nil {
+			return err
 		}
+		return recordEvent(ctx, tx, id, models.TaskEventRestore, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
+	task.DeletedAt = gorm.DeletedAt{}
+	s.notify(ctx, TaskCreated, task)
+	return task, nil
+}
+
+// PurgeTask menghapus permanen task yang sudah berada di trash beserta file
+// cover-nya. Task yang belum dihapus dianggap tidak ditemukan.
+func (s *taskServiceImpl) PurgeTask(ctx context.Context, id uint) error {
+	task, err := s.tasks(ctx).FindTrashedByID(id)
+	if err != nil {
+		return wrapNotFound(id, err)
+	}
+	return s.purge(ctx, task)
+}
 
-	// 3. Hapus data dari database
-	return s.repo.Delete(id)
+// PurgeTrashedBefore menghapus permanen semua task yang masuk trash sebelum
+// cutoff dan mengembalikan jumlah task yang berhasil dihapus.
+func (s *taskServiceImpl) PurgeTrashedBefore(ctx context.Context, cutoff time.Time) (int, error) {
+	tasks, err := s.tasks(ctx).FindTrashedBefore(cutoff)
+	if err != nil {
+		return 0, err
+	}
+	purged := 0
+	for i := range tasks {
+		if err := s.purge(ctx, &tasks[i]); err != nil {
+			return purged, fmt.Errorf("gagal purge task ID %d: %w", tasks[i].ID, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (s *taskServiceImpl) purge(ctx context.Context, task *models.Task) error {
+	err := s.repo.GetDB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := recordEvent(ctx, tx, task.ID, models.TaskEventPurge, nil); err != nil {
+			return err
+		}
+		return s.tasks(ctx).PurgeWithTx(task.ID, tx)
+	})
+	if err != nil {
+		return err
+	}
+
+	// File cover baru dihapus setelah data di database benar-benar hilang.
+	s.removeCoverFiles(ctx, task.CoverPaths())
+	return nil
+}
+
+// Lebar varian cover selain full, yang mengikuti UploadOptions.CoverWidth.
+const (
+	CoverThumbWidth  uint = 320
+	CoverMediumWidth uint = 640
+)
+
+// CoverURLPrefix adalah awal URL cover di storage lokal; routes
+// menyajikannya dari storage cover.
+const CoverURLPrefix = "/static/uploads/tasks/"
+
+// coverVariants adalah ukuran cover yang disimpan. Thumb dan medium tidak
+// pernah lebih lebar dari full.
+func (s *taskServiceImpl) coverVariants() []utils.ImageVariant {
+	full := s.uploads.CoverWidth
+	return []utils.ImageVariant{
+		{Name: models.CoverThumb, Width: min(CoverThumbWidth, full)},
+		{Name: models.CoverMedium, Width: min(CoverMediumWidth, full)},
+		{Name: models.CoverFull, Width: full},
+	}
+}
+
+// saveCover menulis varian cover dari data hasil checkCover lalu
+// mencatatnya di task dalam tx dan mengembalikan URL file yang ditulis. Task.Cover menunjuk ke
+// varian full. File yang sudah ditulis dihapus lagi jika penyimpanan ke
+// database gagal; setelah saveCover berhasil, pemanggil yang menghapusnya
+// jika tx tidak jadi di-commit.
+func (s *taskServiceImpl) saveCover(ctx context.Context, tx *gorm.DB, task *models.Task, data []byte) ([]string, error) {
+	base := "task_" + strconv.FormatUint(uint64(task.ID), 10) + "_" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	images, err := utils.EncodeImageVariants(data, s.coverVariants(), s.imageLimits())
+	if err != nil {
+		return nil, err
+	}
+
+	variants := make([]models.CoverVariant, 0, len(images))
+	written := make([]string, 0, len(images))
+	var cover string
+	for _, img := range images {
+		key := base + "_" + img.Name + img.Ext
+		if err := s.covers.Put(ctx, key, bytes.NewReader(img.Data), img.ContentType); err != nil {
+			s.removeCoverFiles(ctx, written)
+			return nil, err
+		}
+		url := s.covers.URL(key)
+		written = append(written, url)
+		variants = append(variants, models.CoverVariant{Name: img.Name, Path: url, Width: img.Width, Height: img.Height})
+		if img.Name == models.CoverFull {
+			cover = url
+		}
+	}
+	err = tx.Model(task).Update("cover", cover).Error
+	if err == nil {
+		err = s.tasks(ctx).ReplaceCoverVariantsWithTx(task, variants, tx)
+	}
+	if err != nil {
+		s.removeCoverFiles(ctx, written)
+		return nil, err
+	}
+	task.Cover = cover
+	return written, nil
+}
+
+func (s *taskServiceImpl) removeCoverFiles(ctx context.Context, covers []string) {
+	for _, cover := range covers {
+		s.removeCoverFile(ctx, cover)
+	}
+}
+
+// removeCoverFile menghapus file milik URL cover dari storage. Kegagalan
+// hanya dicatat karena perubahan database sudah tersimpan atau dibatalkan;
+// file mungkin sudah tidak ada, storage tidak bisa dihubungi, dll.
+func (s *taskServiceImpl) removeCoverFile(ctx context.Context, cover string) {
+	if cover == "" {
+		return
+	}
+	// Penghapusan tetap dijalankan meskipun ctx request sudah dibatalkan,
+	// misalnya ketika commit gagal karena klien memutus koneksi.
+	key := storage.KeyFromURL(cover)
+	if err := s.covers.Delete(context.WithoutCancel(ctx), key); err != nil {
+		fmt.Printf("Peringatan: Gagal menghapus file cover %s: %v\n", key, err)
+	}
+}
+
+// tasks mengembalikan repository yang dibatasi pada task milik pengguna di
+// ctx, atau repository tanpa batasan untuk pemanggil sistem.
+func (s *taskServiceImpl) tasks(ctx context.Context) repositories.TaskRepository {
+	if user, ok := UserFromContext(ctx); ok {
+		return s.repo.WithOwner(user.ID)
+	}
+	return s.repo
+}
+
+// wrapNotFound menerjemahkan gorm.ErrRecordNotFound menjadi ErrTaskNotFound
+// supaya controller tidak perlu tahu detail GORM.
+func wrapNotFound(id uint, err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("task dengan ID %d: %w", id, ErrTaskNotFound)
+	}
+	return fmt.Errorf("gagal menemukan tugas dengan ID %d: %w", id, err)
+}
+
+// normalizeTaskTags mengambil nama tag dari input task, menormalisasinya dan
+// memastikan panjangnya masuk akal.
+func normalizeTaskTags(tags []models.Tag) ([]string, error) {
+	names := models.NormalizeTagNames(models.TagNames(tags))
+	for _, name := range names {
+		if utf8.RuneCountInString(name) > models.MaxTagNameLength {
+			return nil, fmt.Errorf("%w: tag %q lebih dari %d karakter", ErrInvalidTag, string([]rune(name)[:20])+"...", models.MaxTagNameLength)
+		}
+	}
+	return names, nil
+}
+
+// checkCover menolak cover sebelum transaksi dimulai: ukuran file, format
+// dari magic byte dan dimensi dari header gambar. Error format dan dimensi
+// adalah utils.ErrUnsupportedImage dan utils.ErrImageTooLarge. Isi file
+// dikembalikan supaya saveCover tidak membacanya lagi; nil jika tidak ada
+// cover.
+func (s *taskServiceImpl) checkCover(coverFile *multipart.FileHeader) ([]byte, error) {
+	if coverFile == nil {
+		return nil, nil
+	}
+	if coverFile.Size > s.uploads.MaxBytes {
+		return nil, fmt.Errorf("%w: %d byte, maksimum %d byte", ErrCoverTooLarge, coverFile.Size, s.uploads.MaxBytes)
+	}
+	data, err := readCover(coverFile)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := utils.CheckImage(data, s.imageLimits()); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *taskServiceImpl) imageLimits() utils.ImageLimits {
+	return utils.ImageLimits{MaxDimension: s.uploads.MaxDimension, MaxPixels: s.uploads.MaxPixels}
+}
+
+func readCover(coverFile *multipart.FileHeader) ([]byte, error) {
+	src, err := coverFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	return io.ReadAll(src)
+}
+
+// checkLauncher menerima nama kosong, yang berarti task tidak memilih
+// launcher.
+func (s *taskServiceImpl) checkLauncher(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, ok := s.launchers.Get(name); !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownLauncher, name)
+	}
+	return nil
+}
+
+// checkTransition memvalidasi perubahan status; to kosong berarti status
+// tidak diubah.
+func (s *taskServiceImpl) checkTransition(from, to string) error {
+	if to == "" || to == from {
+		return nil
+	}
+	if !s.workflow.IsValid(to) {
+		return fmt.Errorf("%w: %q", ErrUnknownStatus, to)
+	}
+	if !s.workflow.CanTransition(from, to) {
+		return &TransitionError{From: from, To: to}
+	}
+	return nil
+}
+
+// recordEvent menulis TaskEvent di dalam tx yang sama dengan perubahan task.
+func recordEvent(ctx context.Context, tx *gorm.DB, taskID uint, operation string, changes models.FieldChanges) error {
+	return tx.Create(&models.TaskEvent{
+		TaskID:    taskID,
+		Operation: operation,
+		Actor:     ActorFromContext(ctx),
+		Changes:   changes,
+	}).Error
+}
+
+// recordUpdateEvents memisahkan pergantian cover dari perubahan field lain
+// supaya timeline bisa menampilkannya sebagai operasi tersendiri.
+func recordUpdateEvents(ctx context.Context, tx *gorm.DB, before, after *models.Task) error {
+	var fields, cover models.FieldChanges
+	for _, change := range models.DiffTask(before, after) {
+		if change.Field == "cover" {
+			cover = append(cover, change)
+		} else {
+			fields = append(fields, change)
+		}
+	}
+	if len(fields) > 0 {
+		if err := recordEvent(ctx, tx, after.ID, models.TaskEventUpdate, fields); err != nil {
+			return err
+		}
+	}
+	if len(cover) > 0 {
+		return recordEvent(ctx, tx, after.ID, models.TaskEventCover, cover)
+	}
+	return nil
 }
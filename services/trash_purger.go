@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// RunTrashPurger menghapus permanen task yang sudah berada di trash lebih
+// lama dari retention, sekali saat dijalankan lalu setiap interval, sampai
+// ctx dibatalkan. Retention nol atau negatif menonaktifkan purge otomatis.
+func RunTrashPurger(ctx context.Context, service TaskService, retention, interval time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	ctx = WithActor(ctx, SystemActor)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		purged, err := service.PurgeTrashedBefore(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Gagal membersihkan trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("%d task di trash dihapus permanen", purged)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/storage"
+)
+
+// DefaultUploadGCGrace adalah umur minimum file orphan sebelum dihapus.
+// File cover ditulis sebelum transaksi task di-commit, jadi file yang
+// masih baru bisa saja milik upload yang sedang berjalan.
+const DefaultUploadGCGrace = 24 * time.Hour
+
+type UploadGCService interface {
+	// Collect mencocokkan file di storage dengan cover semua task, termasuk
+	// task di trash. File yang tidak dipakai task mana pun dan lebih tua dari
+	// opts.Grace dihapus, kecuali opts.DryRun. Report tetap dikembalikan
+	// bersama error jika sebagian file gagal dihapus.
+	Collect(ctx context.Context, opts UploadGCOptions) (*UploadGCReport, error)
+}
+
+type UploadGCOptions struct {
+	// Grace adalah umur minimum file orphan yang boleh dihapus.
+	Grace time.Duration
+	// DryRun hanya melaporkan tanpa menghapus apa pun.
+	DryRun bool
+}
+
+type UploadGCReport struct {
+	// Orphans adalah semua file yang tidak dipakai task, termasuk yang
+	// belum melewati grace.
+	Orphans []storage.Object
+	// Deleted adalah key orphan yang dihapus (atau akan dihapus jika
+	// DryRun).
+	Deleted []string
+	// Dangling adalah cover task yang filenya tidak ada di storage. Hanya
+	// dilaporkan; task tidak diubah.
+	Dangling []DanglingCover
+}
+
+// DanglingCover adalah satu path cover task yang filenya hilang.
+type DanglingCover struct {
+	TaskID uint
+	Path   string
+}
+
+type uploadGCServiceImpl struct {
+	repo   repositories.TaskRepository
+	covers storage.Storage
+}
+
+// NewUploadGCService membutuhkan repository tanpa batasan pemilik supaya
+// cover milik semua pengguna ikut dihitung sebagai dipakai.
+func NewUploadGCService(repo repositories.TaskRepository, covers storage.Storage) UploadGCService {
+	return &uploadGCServiceImpl{repo: repo, covers: covers}
+}
+
+func (g *uploadGCServiceImpl) Collect(ctx context.Context, opts UploadGCOptions) (*UploadGCReport, error) {
+	// Storage dibaca sebelum database. File yang ditulis setelah List tidak
+	// ikut diperiksa, dan file yang task-nya di-commit setelah database
+	// dibaca masih dilindungi grace.
+	objects, err := g.covers.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca daftar file cover: %w", err)
+	}
+	active, err := g.repo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	trashed, err := g.repo.FindTrash()
+	if err != nil {
+		return nil, err
+	}
+
+	stored := make(map[string]bool, len(objects))
+	for _, obj := range objects {
+		stored[obj.Key] = true
+	}
+	report := &UploadGCReport{}
+	used := map[string]bool{}
+	for _, task := range append(active, trashed...) {
+		for _, path := range task.CoverPaths() {
+			key := storage.KeyFromURL(path)
+			used[key] = true
+			if !stored[key] {
+				report.Dangling = append(report.Dangling, DanglingCover{TaskID: task.ID, Path: path})
+			}
+		}
+	}
+
+	cutoff := time.Now().Add(-opts.Grace)
+	var errs []error
+	for _, obj := range objects {
+		if used[obj.Key] {
+			continue
+		}
+		report.Orphans = append(report.Orphans, obj)
+		if obj.ModTime.After(cutoff) {
+			continue
+		}
+		if !opts.DryRun {
+			if err := g.covers.Delete(ctx, obj.Key); err != nil {
+				errs = append(errs, fmt.Errorf("gagal menghapus %s: %w", obj.Key, err))
+				continue
+			}
+		}
+		report.Deleted = append(report.Deleted, obj.Key)
+	}
+	return report, errors.Join(errs...)
+}
+
+// RunUploadGC menghapus file cover orphan yang lebih tua dari grace setiap
+// interval sampai ctx dibatalkan. Interval nol atau negatif menonaktifkan
+// pembersihan otomatis.
+func RunUploadGC(ctx context.Context, gc UploadGCService, grace, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		report, err := gc.Collect(ctx, UploadGCOptions{Grace: grace})
+		if err != nil {
+			log.Printf("Gagal membersihkan file cover: %v", err)
+		}
+		if report != nil {
+			if len(report.Deleted) > 0 {
+				log.Printf("%d file cover orphan dihapus", len(report.Deleted))
+			}
+			if len(report.Dangling) > 0 {
+				log.Printf("Peringatan: %d cover task tidak ditemukan di storage; lihat daftarnya dengan `tasktracker gc-uploads -dry-run`", len(report.Dangling))
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config menunjuk bucket S3 atau layanan yang kompatibel. Object diakses
+// dengan path-style (endpoint/bucket/key) supaya MinIO dan sejenisnya
+// bekerja tanpa DNS per bucket.
+type S3Config struct {
+	// Endpoint adalah URL dasar layanan, misalnya https://s3.eu-west-1.amazonaws.com
+	// atau http://localhost:9000.
+	Endpoint        string `yaml:"endpoint"`
+	Region          string `yaml:"region"`
+	Bucket          string `yaml:"bucket"`
+	AccessKeyID     string `yaml:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key"`
+	// PublicURL, jika diisi, dipakai sebagai awal URL cover sehingga browser
+	// mengambil file langsung dari bucket atau CDN, bukan lewat aplikasi.
+	// Bucket harus bisa dibaca publik; cover tidak lagi memerlukan login
+	// dan bisa dibuka siapa pun yang tahu URL-nya, termasuk pengguna lain.
+	PublicURL string `yaml:"public_url"`
+}
+
+// S3 menyimpan file di bucket S3 dengan request yang ditandatangani AWS
+// Signature Version 4.
+type S3 struct {
+	cfg       S3Config
+	endpoint  *url.URL
+	urlPrefix string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// S3Option mengatur dependensi opsional S3.
+type S3Option func(*S3)
+
+// WithHTTPClient mengganti http.DefaultClient.
+func WithHTTPClient(client *http.Client) S3Option {
+	return func(s *S3) {
+		s.client = client
+	}
+}
+
+// WithClock mengganti waktu yang dipakai untuk tanda tangan.
+func WithClock(now func() time.Time) S3Option {
+	return func(s *S3) {
+		s.now = now
+	}
+}
+
+// NewS3 memvalidasi cfg. Tanpa PublicURL, URL key adalah urlPrefix + key dan
+// aplikasi meneruskan file dari bucket lewat Serve.
+func NewS3(cfg S3Config, urlPrefix string, opts ...S3Option) (*S3, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("endpoint S3 %q harus URL http atau https", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" || cfg.Region == "" || cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("bucket, region, access key dan secret key S3 wajib diisi")
+	}
+	s := &S3{
+		cfg:       cfg,
+		endpoint:  endpoint,
+		urlPrefix: urlPrefix,
+		client:    http.DefaultClient,
+		now:       time.Now,
+	}
+	if cfg.PublicURL != "" {
+		s.urlPrefix = strings.TrimRight(cfg.PublicURL, "/") + "/"
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	// Isi dibaca penuh karena SigV4 menandatangani hash payload. Cover
+	// sudah dibatasi UploadOptions.MaxBytes dan di-resize.
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, header, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(http.MethodPut, key, resp)
+	}
+	return nil
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+		return &s3Object{ReadCloser: resp.Body, modTime: modTime}, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s.responseError(http.MethodGet, key, resp)
+	}
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// S3 menjawab 204 juga untuk key yang tidak ada.
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.responseError(http.MethodDelete, key, resp)
+	}
+	return nil
+}
+
+func (s *S3) URL(key string) string {
+	return s.urlPrefix + key
+}
+
+// listBucketResult adalah bagian response ListObjectsV2 yang dipakai List.
+type listBucketResult struct {
+	Contents []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List memakai ListObjectsV2. Key yang berisi "/" dilewati karena bukan
+// key yang dibuat Storage ini.
+func (s *S3) List(ctx context.Context) ([]Object, error) {
+	var objects []Object
+	query := url.Values{"list-type": {"2"}}
+	for {
+		resp, err := s.doQuery(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			return nil, s.responseError(http.MethodGet, "?list-type=2", resp)
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("S3 list: response tidak valid: %w", err)
+		}
+		for _, c := range result.Contents {
+			if checkKey(c.Key) == nil {
+				objects = append(objects, Object{Key: c.Key, Size: c.Size, ModTime: c.LastModified})
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+type s3Object struct {
+	io.ReadCloser
+	modTime time.Time
+}
+
+func (o *s3Object) ModTime() time.Time {
+	return o.modTime
+}
+
+func (s *S3) responseError(method, key string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("S3 %s %s: status %d: %s", method, key, resp.StatusCode, bytes.TrimSpace(msg))
+}
+
+func (s *S3) do(ctx context.Context, method, key string, header http.Header, body []byte) (*http.Response, error) {
+	return s.doQuery(ctx, method, key, nil, header, body)
+}
+
+// doQuery mengirim request untuk object key; key kosong berarti bucket itu
+// sendiri.
+func (s *S3) doQuery(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimRight(u.Path, "/") + "/" + s.cfg.Bucket + "/" + key
+	u.RawQuery = canonicalQuery(query)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	s.sign(req, body)
+	return s.client.Do(req)
+}
+
+// sign menambahkan header Authorization AWS Signature Version 4. Header
+// yang ditandatangani adalah host, semua x-amz-* dan content-type.
+func (s *S3) sign(req *http.Request, body []byte) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signed := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			signed[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(signed))
+	for name := range signed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + signed[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.cfg.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+// canonicalQuery mengurutkan parameter dan meng-encode-nya seperti yang
+// diminta SigV4 (spasi menjadi %20, bukan +, dan ~ tidak di-encode).
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func uriEncode(s string) string {
+	return strings.NewReplacer("+", "%20", "%7E", "~").Replace(url.QueryEscape(s))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
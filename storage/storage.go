@@ -0,0 +1,97 @@
+// Package storage menyimpan file cover di luar database. Local menyimpan di
+// direktori pada host aplikasi, S3 di bucket S3 atau layanan yang kompatibel
+// (MinIO, R2, dll).
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound dikembalikan Get ketika key tidak ada.
+	ErrNotFound = errors.New("file tidak ditemukan")
+	// ErrInvalidKey dikembalikan untuk key yang bukan nama file sederhana.
+	ErrInvalidKey = errors.New("key storage tidak valid")
+)
+
+// Storage menyimpan file berdasarkan key. Key adalah nama file tanpa
+// direktori, misalnya task_1_1700000000_full.jpg.
+type Storage interface {
+	// Put menulis isi r ke key, menimpa file yang sudah ada. Pembaca tidak
+	// pernah melihat file yang baru setengah ditulis.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	// Get membuka key; ErrNotFound jika tidak ada. Pemanggil menutup
+	// hasilnya.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete menghapus key. Key yang tidak ada bukan error.
+	Delete(ctx context.Context, key string) error
+	// URL adalah alamat yang disimpan di database dan dipakai halaman untuk
+	// menampilkan key.
+	URL(key string) string
+	// List mengembalikan semua file, diurutkan menurut key.
+	List(ctx context.Context) ([]Object, error)
+}
+
+// Object adalah satu file hasil List.
+type Object struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// KeyFromURL mengembalikan key dari URL yang dibuat Storage.URL.
+func KeyFromURL(url string) string {
+	return path.Base(url)
+}
+
+// checkKey hanya menerima nama file tanpa pemisah direktori supaya key dari
+// URL tidak bisa keluar dari direktori atau bucket.
+func checkKey(key string) error {
+	if key == "" || key == "." || key == ".." || strings.ContainsAny(key, "/\\\x00") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// Serve menyajikan key dari s. File lokal disajikan dengan http.ServeContent
+// sehingga Range dan If-Modified-Since tetap berlaku.
+func Serve(w http.ResponseWriter, r *http.Request, s Storage, key string) {
+	rc, err := s.Get(r.Context(), key)
+	if errors.Is(err, ErrNotFound) || errors.Is(err, ErrInvalidKey) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("Gagal membaca %s dari storage: %v", key, err)
+		http.Error(w, "Gagal membaca file", http.StatusBadGateway)
+		return
+	}
+	defer rc.Close()
+
+	if f, ok := rc.(interface {
+		io.ReadSeeker
+		Stat() (fs.FileInfo, error)
+	}); ok {
+		if info, err := f.Stat(); err == nil {
+			http.ServeContent(w, r, key, info.ModTime(), f)
+			return
+		}
+	}
+	if ct := mime.TypeByExtension(path.Ext(key)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+	if mt, ok := rc.(interface{ ModTime() time.Time }); ok && !mt.ModTime().IsZero() {
+		w.Header().Set("Last-Modified", mt.ModTime().UTC().Format(http.TimeFormat))
+	}
+	io.Copy(w, rc)
+}
@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/storage"
+	"github.com/nabilulilalbab/welcomesite/utils"
+)
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	names := make([]string, len(entries))
+	for i, e := range entries {
+		names[i] = e.Name()
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cover.jpg")
+	require.NoError(t, os.WriteFile(path, []byte("lama"), 0o644))
+
+	// Write yang gagal di tengah jalan tidak menyentuh file lama.
+	err := utils.WriteFileAtomic(path, func(w io.Writer) error {
+		io.WriteString(w, "setengah")
+		return errors.New("encode gagal")
+	})
+	require.Error(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "lama", string(data))
+	assert.Equal(t, []string{"cover.jpg"}, dirNames(t, dir))
+
+	require.NoError(t, utils.WriteFileAtomic(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, "baru")
+		return err
+	}))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "baru", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	// Rename gagal karena tujuannya direktori; file sementara ikut dihapus.
+	blocked := filepath.Join(dir, "blok")
+	require.NoError(t, os.Mkdir(blocked, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(blocked, "isi"), nil, 0o644))
+	err = utils.WriteFileAtomic(blocked, func(w io.Writer) error { return nil })
+	require.Error(t, err)
+	assert.Equal(t, []string{"blok", "cover.jpg"}, dirNames(t, dir))
+}
+
+// failingStorage gagal pada Put ke-failAt; Put lain diteruskan.
+type failingStorage struct {
+	storage.Storage
+	puts, failAt int
+}
+
+func (f *failingStorage) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	f.puts++
+	if f.puts == f.failAt {
+		return errors.New("put gagal")
+	}
+	return f.Storage.Put(ctx, key, r, contentType)
+}
+
+func TestSaveCoverRemovesWrittenVariantsOnPutFailure(t *testing.T) {
+	uploads := t.TempDir()
+	covers := &failingStorage{Storage: storage.NewLocal(uploads, services.CoverURLPrefix), failAt: 2}
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), uploads,
+		services.WithStorage(covers))
+
+	_, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"},
+		createMultipartFileHeader(t, "cover", "cover.jpg", encodeJPEG(t, 100, 50)))
+	require.Error(t, err)
+	assert.Equal(t, 2, covers.puts)
+	assert.Empty(t, dirNames(t, uploads))
+}
+
+// keepDBAlive menahan satu koneksi tambahan. Context yang dibatalkan membuang
+// koneksi transaksi, dan database memory hilang bersama koneksi terakhirnya.
+func keepDBAlive(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	conn, err := sqlDB.Conn(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+}
+
+// waitForRollback menunggu transaksi yang context-nya dibatalkan selesai
+// di-rollback. database/sql melakukan rollback itu di goroutine lain, dan
+// selama itu tabel tasks di database shared-cache masih terkunci.
+func waitForRollback(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		var n int64
+		return sqlDB.QueryRow("SELECT COUNT(*) FROM tasks").Scan(&n) == nil
+	}, 5*time.Second, 10*time.Millisecond, "tabel tasks masih terkunci")
+}
+
+// coverFailure adalah satu langkah setelah file cover baru ditulis yang
+// bisa digagalkan.
+type coverFailure struct {
+	name  string
+	table string
+	// update menggagalkan UPDATE, selain itu INSERT.
+	update bool
+	// commit membiarkan statement berhasil lalu membatalkan context,
+	// sehingga yang gagal adalah commit transaksi.
+	commit bool
+}
+
+var coverFailures = []coverFailure{
+	{name: "update cover", table: "tasks", update: true},
+	{name: "simpan varian", table: "cover_variants"},
+	{name: "catat event", table: "task_events"},
+	{name: "commit", table: "task_events", commit: true},
+}
+
+// inject memasang callback yang menggagalkan langkah f, tetapi hanya setelah
+// uploads berisi lebih dari existing file, yaitu setelah cover baru ditulis.
+// Hasilnya melaporkan apakah kegagalan benar-benar disuntikkan.
+func (f coverFailure) inject(t *testing.T, db *gorm.DB, uploads string, existing int, cancel context.CancelFunc) func() bool {
+	t.Helper()
+	injected := false
+	fn := func(tx *gorm.DB) {
+		if injected || tx.Statement.Table != f.table || len(dirNames(t, uploads)) <= existing {
+			return
+		}
+		injected = true
+		if f.commit {
+			cancel()
+			return
+		}
+		tx.AddError(errors.New("kegagalan disuntikkan"))
+	}
+	const name = "test:cover_failure"
+	switch {
+	case f.update:
+		require.NoError(t, db.Callback().Update().Before("gorm:update").Register(name, fn))
+	case f.commit:
+		require.NoError(t, db.Callback().Create().After("gorm:create").Register(name, fn))
+	default:
+		require.NoError(t, db.Callback().Create().Before("gorm:create").Register(name, fn))
+	}
+	return func() bool { return injected }
+}
+
+func TestCreateTaskRemovesNewCoverOnFailure(t *testing.T) {
+	for _, f := range coverFailures {
+		t.Run(f.name, func(t *testing.T) {
+			db := newIsolatedDB(t)
+			keepDBAlive(t, db)
+			uploads := t.TempDir()
+			service := services.NewTaskService(repositories.NewTaskRepository(db), uploads)
+			cctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			injected := f.inject(t, db, uploads, 0, cancel)
+
+			_, err := service.CreateTask(cctx, &models.Task{Judul: "A", Tipe: "Website"},
+				createMultipartFileHeader(t, "cover", "cover.jpg", encodeJPEG(t, 40, 20)))
+			require.Error(t, err)
+			require.True(t, injected(), "kegagalan tidak pernah disuntikkan")
+			waitForRollback(t, db)
+
+			assert.Empty(t, dirNames(t, uploads))
+			var count int64
+			require.NoError(t, db.Unscoped().Model(&models.Task{}).Count(&count).Error)
+			assert.Zero(t, count)
+		})
+	}
+}
+
+func TestUpdateTaskKeepsOldCoverOnFailure(t *testing.T) {
+	for _, f := range coverFailures {
+		t.Run(f.name, func(t *testing.T) {
+			service, db, coverFile, task := newCoverFixture(t)
+			keepDBAlive(t, db)
+			uploads := filepath.Dir(coverFile)
+			before := dirNames(t, uploads)
+			require.Len(t, before, 3)
+			cctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			injected := f.inject(t, db, uploads, len(before), cancel)
+
+			_, err := service.UpdateTask(cctx, task.ID, &models.Task{Judul: "Baru"},
+				createMultipartFileHeader(t, "cover", "baru.jpg", encodeJPEG(t, 60, 30)))
+			require.Error(t, err)
+			require.True(t, injected(), "kegagalan tidak pernah disuntikkan")
+			waitForRollback(t, db)
+
+			// File cover baru dihapus, file lama tetap ada dan masih dipakai.
+			assert.Equal(t, before, dirNames(t, uploads))
+			stored, err := service.GetTaskByID(ctx, task.ID)
+			require.NoError(t, err)
+			assert.Equal(t, "A", stored.Judul)
+			assert.Equal(t, task.Cover, stored.Cover)
+			assert.ElementsMatch(t, task.CoverPaths(), stored.CoverPaths())
+		})
+	}
+}
@@ -0,0 +1,450 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/controllers"
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/storage"
+)
+
+func TestAuthServiceRegisterAndLogin(t *testing.T) {
+	db := newIsolatedDB(t)
+	auth := services.NewAuthService(repositories.NewUserRepository(db), services.WithSessionTTL(time.Hour))
+
+	for _, tc := range []struct{ username, password string }{
+		{"ab", "rahasia123"},
+		{"budi santoso", "rahasia123"},
+		{"budi", "pendek"},
+		{"budi", strings.Repeat("x", 73)},
+	} {
+		_, err := auth.Register(ctx, tc.username, tc.password)
+		assert.Error(t, err, tc.username)
+	}
+
+	user, err := auth.Register(ctx, " budi ", "rahasia123")
+	require.NoError(t, err)
+	assert.Equal(t, "budi", user.Username)
+	assert.NotContains(t, user.PasswordHash, "rahasia123")
+	encoded, err := json.Marshal(user)
+	require.NoError(t, err)
+	assert.NotContains(t, string(encoded), "password")
+	_, err = auth.Register(ctx, "budi", "lainlain123")
+	assert.ErrorIs(t, err, services.ErrUsernameTaken)
+
+	_, _, err = auth.Login(ctx, "budi", "salah12345")
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+	_, _, err = auth.Login(ctx, "tidakada", "rahasia123")
+	assert.ErrorIs(t, err, services.ErrInvalidCredentials)
+
+	token, session, err := auth.Login(ctx, "budi", "rahasia123")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), session.ExpiresAt, time.Minute)
+	assert.NotEqual(t, token, session.ID, "database hanya menyimpan hash token")
+
+	current, err := auth.Authenticate(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, current.ID)
+	_, err = auth.Authenticate(ctx, "token-palsu")
+	assert.ErrorIs(t, err, services.ErrUnauthenticated)
+
+	require.NoError(t, auth.Logout(ctx, token))
+	_, err = auth.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, services.ErrUnauthenticated)
+
+	// Sesi kedaluwarsa ditolak lalu dihapus.
+	token, _, err = auth.Login(ctx, "budi", "rahasia123")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.Session{}).Where("user_id = ?", user.ID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = auth.Authenticate(ctx, token)
+	assert.ErrorIs(t, err, services.ErrUnauthenticated)
+	var sessions int64
+	require.NoError(t, db.Model(&models.Session{}).Count(&sessions).Error)
+	assert.Zero(t, sessions)
+}
+
+func TestFirstUserAdoptsExistingTasks(t *testing.T) {
+	db := newIsolatedDB(t)
+	service := services.NewTaskService(repositories.NewTaskRepository(db), t.TempDir())
+	auth := services.NewAuthService(repositories.NewUserRepository(db))
+
+	// Task yang dibuat sebelum ada akun tidak memiliki pemilik.
+	active, err := service.CreateTask(ctx, &models.Task{Judul: "Lama", Tipe: "Website"}, nil)
+	require.NoError(t, err)
+	trashed, err := service.CreateTask(ctx, &models.Task{Judul: "Di trash", Tipe: "Website"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTask(ctx, trashed.ID))
+	assert.Zero(t, active.OwnerID)
+
+	first, err := auth.Register(ctx, "pertama", "rahasia123")
+	require.NoError(t, err)
+	orphan, err := service.CreateTask(ctx, &models.Task{Judul: "Yatim", Tipe: "Website"}, nil)
+	require.NoError(t, err)
+	_, err = auth.Register(ctx, "kedua", "rahasia123")
+	require.NoError(t, err)
+
+	asFirst := services.WithUser(ctx, first)
+	page, err := service.ListTasks(asFirst, models.TaskQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Lama"}, judulOf(page.Tasks))
+	trash, err := service.ListTrash(asFirst)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Di trash"}, judulOf(trash))
+
+	// Hanya pengguna pertama yang mengadopsi task.
+	var owner uint
+	require.NoError(t, db.Model(&models.Task{}).Select("owner_id").Where("id = ?", orphan.ID).Scan(&owner).Error)
+	assert.Zero(t, owner)
+}
+
+func TestTasksScopedToOwner(t *testing.T) {
+	db := newIsolatedDB(t)
+	service := services.NewTaskService(repositories.NewTaskRepository(db), t.TempDir())
+	tags := services.NewTagService(repositories.NewTagRepository(db))
+	auth := services.NewAuthService(repositories.NewUserRepository(db))
+	alice, err := auth.Register(ctx, "alice", "rahasia123")
+	require.NoError(t, err)
+	bob, err := auth.Register(ctx, "bob", "rahasia123")
+	require.NoError(t, err)
+	asAlice, asBob := services.WithUser(ctx, alice), services.WithUser(ctx, bob)
+
+	task, err := service.CreateTask(asAlice, &models.Task{Judul: "Milik Alice", Tipe: "Website", Tags: tagList("go")}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, task.OwnerID)
+	_, err = service.CreateTask(asBob, &models.Task{Judul: "Milik Bob", Tipe: "Website", Tags: tagList("go")}, nil)
+	require.NoError(t, err)
+
+	page, err := service.ListTasks(asBob, models.TaskQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Milik Bob"}, judulOf(page.Tasks))
+	assert.Equal(t, int64(1), page.Total)
+
+	_, err = service.GetTaskByID(asBob, task.ID)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+	_, err = service.UpdateTask(asBob, task.ID, &models.Task{Judul: "Dibajak"}, nil)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+	_, err = service.GetTaskEvents(asBob, task.ID)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+	assert.ErrorIs(t, service.DeleteTask(asBob, task.ID), services.ErrTaskNotFound)
+
+	require.NoError(t, service.DeleteTask(asAlice, task.ID))
+	trash, err := service.ListTrash(asBob)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+	_, err = service.RestoreTask(asBob, task.ID)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+	assert.ErrorIs(t, service.PurgeTask(asBob, task.ID), services.ErrTaskNotFound)
+	_, err = service.RestoreTask(asAlice, task.ID)
+	require.NoError(t, err)
+
+	fetched, err := service.GetTaskByID(asAlice, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Milik Alice", fetched.Judul)
+
+	// Setiap pengguna punya tag "go" sendiri.
+	counts, err := tags.GetAllTags(asBob)
+	require.NoError(t, err)
+	require.Len(t, counts, 1)
+	assert.Equal(t, int64(1), counts[0].TaskCount)
+	bobTag := counts[0]
+	counts, err = tags.GetAllTags(asAlice)
+	require.NoError(t, err)
+	require.Len(t, counts, 1)
+	aliceTag := counts[0]
+	assert.NotEqual(t, bobTag.ID, aliceTag.ID)
+	counts, err = tags.GetAllTags(ctx)
+	require.NoError(t, err)
+	assert.Len(t, counts, 2)
+
+	// Tag milik pengguna lain tidak terlihat dan tidak bisa diubah.
+	_, err = tags.RenameTag(asBob, aliceTag.ID, "dibajak")
+	assert.ErrorIs(t, err, services.ErrTagNotFound)
+	assert.ErrorIs(t, tags.DeleteTag(asBob, aliceTag.ID), services.ErrTagNotFound)
+	_, err = tags.MergeTags(asBob, aliceTag.ID, bobTag.ID)
+	assert.ErrorIs(t, err, services.ErrTagNotFound)
+	_, err = tags.MergeTags(asBob, bobTag.ID, aliceTag.ID)
+	assert.ErrorIs(t, err, services.ErrTagNotFound)
+
+	_, err = tags.RenameTag(asBob, bobTag.ID, "golang")
+	require.NoError(t, err)
+	_, err = tags.CreateTag(asBob, "go")
+	require.NoError(t, err, "nama tag hanya unik per pengguna")
+	fetched, err = service.GetTaskByID(asAlice, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go"}, models.TagNames(fetched.Tags))
+	events, err := service.GetTaskEvents(asAlice, task.ID)
+	require.NoError(t, err)
+	for _, event := range events {
+		assert.NotEqual(t, bob.Username, event.Actor)
+	}
+}
+
+func TestRoutesRequireLogin(t *testing.T) {
+	router, _, _ := newAuthRouter(t)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/?status=done")
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/login?next="+url.QueryEscape("/?status=done"), rec.Header().Get("Location"))
+
+	rec = get("/api/v1/tasks")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	var body apiErrorBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "unauthorized", body.Error.Code)
+
+	assert.Equal(t, http.StatusUnauthorized, get("/ws").Code)
+	assert.Equal(t, http.StatusSeeOther, get("/static/uploads/tasks/task_1.jpg").Code)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/task/delete/1", nil))
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/login", rec.Header().Get("Location"))
+
+	rec = get("/login")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `action="/login"`)
+	assert.Contains(t, rec.Body.String(), "tasktracker useradd", "petunjuk membuat akun pertama")
+}
+
+var loginCSRFPattern = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+// loginFormToken membuka halaman login dan mengembalikan cookie serta token
+// CSRF form login-nya.
+func loginFormToken(t *testing.T, router http.Handler) (*http.Cookie, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	m := loginCSRFPattern.FindStringSubmatch(rec.Body.String())
+	require.NotNil(t, m, "form login tidak memuat token CSRF")
+	cookie := findCookie(rec.Result().Cookies(), controllers.LoginCSRFCookieName)
+	require.NotNil(t, cookie)
+	return cookie, m[1]
+}
+
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+// postLogin mengirim form login dengan cookie dan token CSRF yang diberikan;
+// cookie nil berarti tanpa cookie token login.
+func postLogin(router http.Handler, cookie *http.Cookie, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestLoginLogoutFlow(t *testing.T) {
+	router, auth, _ := newAuthRouter(t)
+	_, err := auth.Register(ctx, "budi", "rahasia123")
+	require.NoError(t, err)
+	csrfCookie, csrf := loginFormToken(t, router)
+
+	login := func(password, next string) *httptest.ResponseRecorder {
+		return postLogin(router, csrfCookie, url.Values{
+			"username": {"budi"}, "password": {password}, "next": {next}, controllers.CSRFFieldName: {csrf},
+		})
+	}
+
+	rec := login("salah12345", "/trash")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "username atau password salah")
+	assert.Contains(t, rec.Body.String(), `value="budi"`)
+	assert.Contains(t, rec.Body.String(), csrf, "form yang ditampilkan ulang memakai token yang sama")
+	assert.NotContains(t, rec.Body.String(), "tasktracker useradd")
+
+	// next di luar aplikasi diabaikan.
+	rec = login("rahasia123", "//evil.example.com/")
+	assert.Equal(t, "/", rec.Header().Get("Location"))
+
+	rec = login("rahasia123", "/trash")
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/trash", rec.Header().Get("Location"))
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 2)
+	session := findCookie(cookies, controllers.SessionCookieName)
+	require.NotNil(t, session)
+	assert.Negative(t, findCookie(cookies, controllers.LoginCSRFCookieName).MaxAge, "token login dibuang setelah login")
+	assert.True(t, session.HttpOnly)
+	assert.Equal(t, http.SameSiteLaxMode, session.SameSite)
+
+	withCookie := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.AddCookie(session)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	rec = withCookie(http.MethodGet, "/")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "budi")
+	assert.Contains(t, rec.Body.String(), `action="/logout"`)
+	assert.Equal(t, http.StatusSeeOther, withCookie(http.MethodGet, "/login").Code, "sudah login")
+
+	// Logout memakai token CSRF yang ditulis di halaman.
+	form := url.Values{controllers.CSRFFieldName: {csrfTokenFromPage(t, withCookie(http.MethodGet, "/").Body.String())}}
+	req := httptest.NewRequest(http.MethodPost, "/logout", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(session)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.Equal(t, "/login", rec.Header().Get("Location"))
+	require.Len(t, rec.Result().Cookies(), 1)
+	assert.Negative(t, rec.Result().Cookies()[0].MaxAge)
+
+	rec = withCookie(http.MethodGet, "/api/v1/tasks")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "sesi sudah dihapus")
+}
+
+func TestAPIHidesOtherUsersTasks(t *testing.T) {
+	router, auth, repo := newAuthRouter(t)
+	alice, aliceToken := loginTestUser(t, auth, "alice")
+	_, bobToken := loginTestUser(t, auth, "bob")
+	task, err := repo.WithOwner(alice.ID).Create(&models.Task{Judul: "Rahasia", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+
+	asBob := withSession(router, auth, bobToken)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, asBob, http.MethodGet, path, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, asBob, http.MethodPut, path, map[string]any{"judul": "x", "tipe": "Website"}).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, asBob, http.MethodDelete, path, nil).Code)
+	rec := doJSON(t, asBob, http.MethodGet, "/api/v1/tasks", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "Rahasia")
+
+	rec = doJSON(t, withSession(router, auth, aliceToken), http.MethodGet, path, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCoversOnlyServedToOwner(t *testing.T) {
+	uploads := t.TempDir()
+	router, auth, _ := newAuthRouter(t, services.WithStorage(storage.NewLocal(uploads, services.CoverURLPrefix)))
+	_, aliceToken := loginTestUser(t, auth, "alice")
+	_, bobToken := loginTestUser(t, auth, "bob")
+	asAlice, asBob := withSession(router, auth, aliceToken), withSession(router, auth, bobToken)
+
+	rec := httptest.NewRecorder()
+	asAlice.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/api/v1/tasks", encodeJPEG(t, 40, 20)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	require.NoError(t, os.WriteFile(filepath.Join(uploads, "lain.jpg"), encodeJPEG(t, 4, 4), 0o644))
+
+	for _, path := range created.Data.CoverPaths() {
+		assert.Equal(t, http.StatusOK, doJSON(t, asAlice, http.MethodGet, path, nil).Code, path)
+		assert.Equal(t, http.StatusNotFound, doJSON(t, asBob, http.MethodGet, path, nil).Code, path)
+	}
+	// File yang bukan cover task mana pun tidak disajikan, meski ada.
+	assert.Equal(t, http.StatusNotFound, doJSON(t, asAlice, http.MethodGet, "/static/uploads/tasks/lain.jpg", nil).Code)
+
+	// Cover task di trash tetap bisa dilihat pemiliknya di halaman trash.
+	require.Equal(t, http.StatusNoContent,
+		doJSON(t, asAlice, http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d", created.Data.ID), nil).Code)
+	assert.Equal(t, http.StatusOK, doJSON(t, asAlice, http.MethodGet, created.Data.Cover, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, asBob, http.MethodGet, created.Data.Cover, nil).Code)
+}
+
+// Cover lama tersimpan dengan prefix lokal, sedangkan URL dari S3 dengan
+// PublicURL memakai prefix CDN; kepemilikan tetap dicocokkan lewat key.
+func TestLegacyCoversServedWithPublicURL(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	cfg := fake.config(srv.URL)
+	cfg.PublicURL = "https://cdn.contoh.id/covers/"
+	s3, err := storage.NewS3(cfg, services.CoverURLPrefix)
+	require.NoError(t, err)
+	router, auth, repo := newAuthRouter(t, services.WithStorage(s3))
+	alice, aliceToken := loginTestUser(t, auth, "alice")
+	_, bobToken := loginTestUser(t, auth, "bob")
+
+	legacy := services.CoverURLPrefix + "lama.jpg"
+	require.NotEqual(t, legacy, s3.URL("lama.jpg"))
+	_, err = repo.WithOwner(alice.ID).Create(&models.Task{Judul: "Lama", Tipe: "Website", Status: "todo", Cover: legacy})
+	require.NoError(t, err)
+	require.NoError(t, s3.Put(ctx, "lama.jpg", strings.NewReader("jpeg"), "image/jpeg"))
+
+	assert.Equal(t, http.StatusOK, doJSON(t, withSession(router, auth, aliceToken), http.MethodGet, legacy, nil).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, withSession(router, auth, bobToken), http.MethodGet, legacy, nil).Code)
+}
+
+// Form login dari situs lain tidak bisa membuat korban login ke akun
+// penyerang karena tidak membawa token yang cocok dengan cookie login.
+func TestLoginRequiresCSRFToken(t *testing.T) {
+	router, auth, _ := newAuthRouter(t)
+	_, err := auth.Register(ctx, "penyerang", "rahasia123")
+	require.NoError(t, err)
+	cookie, token := loginFormToken(t, router)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, "/login", cookie.Path)
+	form := func(token string) url.Values {
+		return url.Values{"username": {"penyerang"}, "password": {"rahasia123"}, controllers.CSRFFieldName: {token}}
+	}
+
+	otherCookie, _ := loginFormToken(t, router)
+	for name, rec := range map[string]*httptest.ResponseRecorder{
+		"tanpa cookie":  postLogin(router, nil, form(token)),
+		"tanpa token":   postLogin(router, cookie, form("")),
+		"token salah":   postLogin(router, cookie, form("salah")),
+		"cookie lain":   postLogin(router, otherCookie, form(token)),
+		"cookie kosong": postLogin(router, &http.Cookie{Name: controllers.LoginCSRFCookieName}, form("")),
+	} {
+		assert.Equal(t, http.StatusForbidden, rec.Code, name)
+		assert.Nil(t, findCookie(rec.Result().Cookies(), controllers.SessionCookieName), name)
+	}
+
+	rec := postLogin(router, cookie, form(token))
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	assert.NotNil(t, findCookie(rec.Result().Cookies(), controllers.SessionCookieName))
+}
+
+func TestTagAPIScopedToOwner(t *testing.T) {
+	router, auth, _ := newAuthRouter(t)
+	_, aliceToken := loginTestUser(t, auth, "alice")
+	_, bobToken := loginTestUser(t, auth, "bob")
+	asAlice, asBob := withSession(router, auth, aliceToken), withSession(router, auth, bobToken)
+
+	rec := doJSON(t, asAlice, http.MethodPost, "/api/v1/tags", map[string]any{"name": "go"})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created struct {
+		Data models.Tag `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	// Nama yang sama boleh dipakai pengguna lain.
+	require.Equal(t, http.StatusCreated, doJSON(t, asBob, http.MethodPost, "/api/v1/tags", map[string]any{"name": "go"}).Code)
+
+	path := fmt.Sprintf("/api/v1/tags/%d", created.Data.ID)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, asBob, http.MethodPut, path, map[string]any{"name": "dibajak"}).Code)
+	assert.Equal(t, http.StatusNotFound, doJSON(t, asBob, http.MethodDelete, path, nil).Code)
+	assert.Equal(t, http.StatusOK, doJSON(t, asAlice, http.MethodPut, path, map[string]any{"name": "golang"}).Code)
+}
@@ -0,0 +1,239 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/bmp"
+	"golang.org/x/image/tiff"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/utils"
+)
+
+// webpLossless1x1 adalah WebP lossless 1x1; x/image tidak punya encoder WebP.
+const webpLossless1x1 = "UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA=="
+
+func newVariantService(t *testing.T) (services.TaskService, string) {
+	t.Helper()
+	uploads := t.TempDir()
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), uploads,
+		services.WithUploadOptions(services.UploadOptions{MaxBytes: 10 << 20, CoverWidth: 800}))
+	return service, uploads
+}
+
+func createWithCover(t *testing.T, service services.TaskService, name string, data []byte) *models.Task {
+	t.Helper()
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"},
+		createMultipartFileHeader(t, "cover", name, data))
+	require.NoError(t, err)
+	return task
+}
+
+type variantSize struct {
+	Name          string
+	Width, Height int
+}
+
+func variantSizes(task *models.Task) []variantSize {
+	sizes := make([]variantSize, len(task.CoverVariants))
+	for i, v := range task.CoverVariants {
+		sizes[i] = variantSize{v.Name, v.Width, v.Height}
+	}
+	return sizes
+}
+
+func TestCoverVariantsSizes(t *testing.T) {
+	service, uploads := newVariantService(t)
+	task := createWithCover(t, service, "cover.jpg", encodeJPEG(t, 1000, 500))
+
+	want := []variantSize{{"thumb", 320, 160}, {"medium", 640, 320}, {"full", 800, 400}}
+	assert.Equal(t, want, variantSizes(task))
+	assert.Equal(t, task.CoverVariant(models.CoverFull).Path, task.Cover)
+	for _, v := range task.CoverVariants {
+		assert.True(t, strings.HasPrefix(v.Path, "/static/uploads/tasks/"))
+		f, err := os.Open(filepath.Join(uploads, filepath.Base(v.Path)))
+		require.NoError(t, err)
+		cfg, format, err := image.DecodeConfig(f)
+		f.Close()
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, [2]int{v.Width, v.Height}, [2]int{cfg.Width, cfg.Height}, v.Name)
+	}
+
+	stored, err := service.GetTaskByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, want, variantSizes(stored))
+	assert.Equal(t, fmt.Sprintf("%s 320w, %s 640w, %s 800w",
+		stored.CoverVariant("thumb").Path, stored.CoverVariant("medium").Path, stored.Cover), stored.CoverSrcset())
+}
+
+func TestCoverVariantsNeverUpscale(t *testing.T) {
+	service, _ := newVariantService(t)
+	task := createWithCover(t, service, "kecil.jpg", encodeJPEG(t, 100, 50))
+	for _, v := range task.CoverVariants {
+		assert.Equal(t, [2]int{100, 50}, [2]int{v.Width, v.Height}, v.Name)
+	}
+	// Lebar yang sama hanya muncul sekali di srcset.
+	assert.Equal(t, task.CoverVariants[0].Path+" 100w", task.CoverSrcset())
+}
+
+func TestCoverFormatsSniffedFromContent(t *testing.T) {
+	opaque := image.NewRGBA(image.Rect(0, 0, 30, 20))
+	for i := range opaque.Pix {
+		opaque.Pix[i] = 0xff
+	}
+	encode := func(fn func(*bytes.Buffer) error) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, fn(&buf))
+		return buf.Bytes()
+	}
+	webp, err := base64.StdEncoding.DecodeString(webpLossless1x1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		file    string
+		data    []byte
+		wantExt string
+	}{
+		{"png bernama jpg", "cover.jpg", encode(func(b *bytes.Buffer) error { return png.Encode(b, opaque) }), ".jpg"},
+		{"png transparan", "cover.png", encode(func(b *bytes.Buffer) error { return png.Encode(b, image.NewNRGBA(image.Rect(0, 0, 30, 20))) }), ".png"},
+		{"gif", "cover", encode(func(b *bytes.Buffer) error { return gif.Encode(b, opaque, nil) }), ".jpg"},
+		{"bmp", "cover.bmp", encode(func(b *bytes.Buffer) error { return bmp.Encode(b, opaque) }), ".jpg"},
+		{"tiff", "cover.tif", encode(func(b *bytes.Buffer) error { return tiff.Encode(b, opaque, nil) }), ".jpg"},
+		{"webp", "cover.webp", webp, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service, uploads := newVariantService(t)
+			task := createWithCover(t, service, tc.file, tc.data)
+			require.Len(t, task.CoverVariants, 3)
+			if tc.wantExt != "" {
+				assert.Equal(t, tc.wantExt, filepath.Ext(task.Cover))
+			}
+			assert.FileExists(t, filepath.Join(uploads, filepath.Base(task.Cover)))
+		})
+	}
+
+	service, uploads := newVariantService(t)
+	_, err = service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"},
+		createMultipartFileHeader(t, "cover", "cover.jpg", []byte("bukan gambar")))
+	assert.ErrorIs(t, err, utils.ErrUnsupportedImage)
+	entries, err := os.ReadDir(uploads)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+// withEXIFOrientation menyisipkan segmen APP1 EXIF berisi tag Orientation
+// tepat setelah SOI.
+func withEXIFOrientation(jpg []byte, orientation uint16) []byte {
+	var tiffBlock bytes.Buffer
+	tiffBlock.WriteString("MM\x00\x2a")
+	binary.Write(&tiffBlock, binary.BigEndian, uint32(8))
+	binary.Write(&tiffBlock, binary.BigEndian, uint16(1))
+	binary.Write(&tiffBlock, binary.BigEndian, []uint16{0x0112, 3})
+	binary.Write(&tiffBlock, binary.BigEndian, uint32(1))
+	binary.Write(&tiffBlock, binary.BigEndian, []uint16{orientation, 0})
+	binary.Write(&tiffBlock, binary.BigEndian, uint32(0))
+
+	payload := append([]byte("Exif\x00\x00"), tiffBlock.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, segment...)
+	out = append(out, payload...)
+	return append(out, jpg[2:]...)
+}
+
+func TestCoverEXIFOrientation(t *testing.T) {
+	// Kiri merah, kanan biru; orientasi 6 berarti diputar 90° searah jarum
+	// jam sehingga merah ada di atas.
+	src := image.NewRGBA(image.Rect(0, 0, 40, 20))
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 20 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, src, &jpeg.Options{Quality: 95}))
+
+	service, uploads := newVariantService(t)
+	task := createWithCover(t, service, "foto.jpg", withEXIFOrientation(buf.Bytes(), 6))
+	full := task.CoverVariant(models.CoverFull)
+	assert.Equal(t, [2]int{20, 40}, [2]int{full.Width, full.Height})
+
+	f, err := os.Open(filepath.Join(uploads, filepath.Base(full.Path)))
+	require.NoError(t, err)
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	require.NoError(t, err)
+	r, _, b, _ := img.At(10, 5).RGBA()
+	assert.Greater(t, r, b, "atas harus merah")
+	r, _, b, _ = img.At(10, 35).RGBA()
+	assert.Greater(t, b, r, "bawah harus biru")
+}
+
+func TestReplacingCoverRemovesOldVariants(t *testing.T) {
+	service, uploads := newVariantService(t)
+	task := createWithCover(t, service, "a.jpg", encodeJPEG(t, 1000, 500))
+	old := task.CoverPaths()
+	require.Len(t, old, 3)
+
+	updated, err := service.UpdateTask(ctx, task.ID, &models.Task{},
+		createMultipartFileHeader(t, "cover", "b.jpg", encodeJPEG(t, 900, 300)))
+	require.NoError(t, err)
+	require.Len(t, updated.CoverVariants, 3)
+	for _, path := range old {
+		assert.NoFileExists(t, filepath.Join(uploads, filepath.Base(path)))
+	}
+	for _, path := range updated.CoverPaths() {
+		assert.FileExists(t, filepath.Join(uploads, filepath.Base(path)))
+	}
+
+	removed, err := service.RemoveCover(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Empty(t, removed.CoverVariants)
+	stored, err := service.GetTaskByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored.CoverVariants)
+	entries, err := os.ReadDir(uploads)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestCardUsesThumbnailSrcset(t *testing.T) {
+	router, _ := newTestRouter(t)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/api/v1/tasks", encodeJPEG(t, 1000, 500)))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"cover_variants":[{"name":"thumb"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	page := rec.Body.String()
+	assert.Regexp(t, `src="/static/uploads/tasks/task_\d+_\d+_thumb\.jpg"`, page)
+	assert.Regexp(t, `srcset="/static/uploads/tasks/\S+_thumb\.jpg 320w, \S+_medium\.jpg 640w, \S+_full\.jpg 800w"`, page)
+}
@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/controllers"
+	"github.com/nabilulilalbab/welcomesite/models"
+)
+
+var csrfMetaPattern = regexp.MustCompile(`<meta name="csrf-token" content="([^"]+)"`)
+
+// csrfTokenFromPage mengambil token CSRF dari meta tag halaman.
+func csrfTokenFromPage(t *testing.T, html string) string {
+	t.Helper()
+	m := csrfMetaPattern.FindStringSubmatch(html)
+	require.NotNil(t, m, "halaman tidak memuat meta csrf-token")
+	return m[1]
+}
+
+// sessionRequest membuat request dengan cookie sesi tanpa header CSRF,
+// seperti form yang dikirim dari situs lain.
+func sessionRequest(method, path, sessionToken string, form url.Values) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: controllers.SessionCookieName, Value: sessionToken})
+	return req
+}
+
+func multipartTaskForm(t *testing.T, path, sessionToken, csrfToken string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("judul", "Dari form"))
+	require.NoError(t, writer.WriteField("tipe", "Website"))
+	if csrfToken != "" {
+		require.NoError(t, writer.WriteField(controllers.CSRFFieldName, csrfToken))
+	}
+	require.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, path, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.AddCookie(&http.Cookie{Name: controllers.SessionCookieName, Value: sessionToken})
+	return req
+}
+
+func TestCSRFTokenRenderedInForms(t *testing.T) {
+	router, auth, repo := newAuthRouter(t)
+	user, token := loginTestUser(t, auth, "tester")
+	task, err := repo.WithOwner(user.ID).Create(&models.Task{Judul: "Lama", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	require.NoError(t, repo.WithOwner(user.ID).Delete(task.ID))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, sessionRequest(http.MethodGet, "/", token, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	page := rec.Body.String()
+	csrf := csrfTokenFromPage(t, page)
+	assert.Equal(t, auth.CSRFToken(token), csrf)
+	hidden := fmt.Sprintf(`<input type="hidden" name="csrf_token" value="%s" />`, csrf)
+	// Form tambah, form edit dan form logout; form hapus dibuat JavaScript
+	// dari meta tag.
+	assert.Equal(t, 3, strings.Count(page, hidden))
+	assert.Contains(t, page, `csrf.name = "csrf_token"`)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, sessionRequest(http.MethodGet, "/trash", token, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 2, strings.Count(rec.Body.String(), hidden), "form restore dan purge")
+}
+
+func TestCSRFAcceptsSessionToken(t *testing.T) {
+	router, auth, repo := newAuthRouter(t)
+	user, token := loginTestUser(t, auth, "tester")
+	csrf := auth.CSRFToken(token)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskForm(t, "/task/add", token, csrf))
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	tasks, err := repo.WithOwner(user.ID).FindAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "Dari form", tasks[0].Judul)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, sessionRequest(http.MethodPost, fmt.Sprintf("/task/delete/%d", tasks[0].ID), token,
+		url.Values{controllers.CSRFFieldName: {csrf}}))
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+
+	// Klien API mengirim token lewat header.
+	req := sessionRequest(http.MethodPost, fmt.Sprintf("/api/v1/trash/%d/restore", tasks[0].ID), token, nil)
+	req.Header.Set(controllers.CSRFHeaderName, csrf)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCSRFRejectsMissingOrForeignToken(t *testing.T) {
+	router, auth, repo := newAuthRouter(t)
+	user, token := loginTestUser(t, auth, "tester")
+	_, otherToken := loginTestUser(t, auth, "lain")
+	task, err := repo.WithOwner(user.ID).Create(&models.Task{Judul: "Tetap", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	foreign := auth.CSRFToken(otherToken)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+	}{
+		{"add tanpa token", multipartTaskForm(t, "/task/add", token, "")},
+		{"add token sesi lain", multipartTaskForm(t, "/task/add", token, foreign)},
+		{"update tanpa token", multipartTaskForm(t, fmt.Sprintf("/task/update/%d", task.ID), token, "")},
+		{"delete tanpa token", sessionRequest(http.MethodPost, fmt.Sprintf("/task/delete/%d", task.ID), token, nil)},
+		{"delete token salah", sessionRequest(http.MethodPost, fmt.Sprintf("/task/delete/%d", task.ID), token,
+			url.Values{controllers.CSRFFieldName: {"palsu"}})},
+		{"purge tanpa token", sessionRequest(http.MethodPost, fmt.Sprintf("/trash/%d/purge", task.ID), token, nil)},
+		{"logout tanpa token", sessionRequest(http.MethodPost, "/logout", token, nil)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, tc.req)
+			assert.Equal(t, http.StatusForbidden, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Token CSRF tidak valid")
+		})
+	}
+
+	t.Run("api", func(t *testing.T) {
+		req := sessionRequest(http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d", task.ID), token, nil)
+		req.Header.Set(controllers.CSRFHeaderName, foreign)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "csrf_invalid")
+	})
+
+	tasks, err := repo.WithOwner(user.ID).FindAll()
+	require.NoError(t, err)
+	require.Len(t, tasks, 1, "tidak ada task yang dibuat atau dihapus")
+	assert.Equal(t, "Tetap", tasks[0].Judul)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, sessionRequest(http.MethodGet, "/api/v1/tasks", token, nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "sesi tetap berlaku")
+}
@@ -0,0 +1,317 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/utils"
+)
+
+// requireGit melewati test jika git tidak terpasang, dan mengisolasi git
+// dari konfigurasi global mesin.
+func requireGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git tidak terpasang")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, key := range []string{"GIT_AUTHOR_NAME", "GIT_COMMITTER_NAME"} {
+		t.Setenv(key, "Tester")
+	}
+	for _, key := range []string{"GIT_AUTHOR_EMAIL", "GIT_COMMITTER_EMAIL"} {
+		t.Setenv(key, "tester@example.com")
+	}
+}
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, "git %v: %s", args, out)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func TestReadGitStatus(t *testing.T) {
+	requireGit(t)
+
+	status, err := utils.ReadGitStatus(ctx, t.TempDir())
+	require.NoError(t, err)
+	assert.False(t, status.Repo)
+	assert.False(t, status.CheckedAt.IsZero())
+
+	origin := t.TempDir()
+	git(t, origin, "init", "-q", "-b", "main")
+	writeFile(t, filepath.Join(origin, "README"), "halo\n")
+	status, err = utils.ReadGitStatus(ctx, origin)
+	require.NoError(t, err)
+	assert.True(t, status.Repo)
+	assert.Equal(t, "main", status.Branch)
+	assert.Equal(t, 1, status.Dirty)
+	assert.Nil(t, status.LastCommit, "repositori belum punya commit")
+
+	git(t, origin, "add", "README")
+	git(t, origin, "commit", "-q", "-m", "Commit pertama")
+	clone := filepath.Join(t.TempDir(), "clone")
+	git(t, origin, "clone", "-q", origin, clone)
+
+	writeFile(t, filepath.Join(clone, "README"), "diubah\n")
+	writeFile(t, filepath.Join(clone, "baru.txt"), "baru\n")
+	status, err = utils.ReadGitStatus(ctx, clone)
+	require.NoError(t, err)
+	assert.Equal(t, "main", status.Branch)
+	assert.Equal(t, "origin/main", status.Upstream)
+	assert.Equal(t, 2, status.Dirty)
+	assert.Zero(t, status.Ahead)
+	require.NotNil(t, status.LastCommit)
+	assert.Equal(t, "Commit pertama", status.LastCommit.Message)
+	assert.Len(t, status.LastCommit.Hash, 40)
+	assert.WithinDuration(t, time.Now(), status.LastCommit.Date, time.Minute)
+
+	git(t, clone, "commit", "-q", "-am", "Commit di clone")
+	writeFile(t, filepath.Join(origin, "lain.txt"), "lain\n")
+	git(t, origin, "add", "lain.txt")
+	git(t, origin, "commit", "-q", "-m", "Commit di origin")
+	git(t, clone, "fetch", "-q")
+	status, err = utils.ReadGitStatus(ctx, clone)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.Ahead)
+	assert.Equal(t, 1, status.Behind)
+	assert.Equal(t, 1, status.Dirty, "hanya baru.txt yang tersisa")
+	assert.Equal(t, "Commit di clone", status.LastCommit.Message)
+
+	git(t, clone, "checkout", "-q", "--detach")
+	status, err = utils.ReadGitStatus(ctx, clone)
+	require.NoError(t, err)
+	assert.True(t, status.Repo)
+	assert.Empty(t, status.Branch, "HEAD terlepas")
+}
+
+func TestReadGitStatusIgnoresRepositoryFSMonitor(t *testing.T) {
+	requireGit(t)
+	dir := hostileDir(t)
+	git(t, dir, "init", "-q")
+	// fsmonitor di konfigurasi repositori dijalankan git status; proyek yang
+	// diperiksa tidak boleh bisa menjalankan program lewat tracker.
+	marker := filepath.Join(t.TempDir(), "fsmonitor-dijalankan")
+	hook := filepath.Join(t.TempDir(), "hook.sh")
+	require.NoError(t, os.WriteFile(hook, []byte("#!/bin/sh\ntouch "+marker+"\n"), 0o755))
+	git(t, dir, "config", "core.fsmonitor", hook)
+
+	status, err := utils.ReadGitStatus(ctx, dir)
+	require.NoError(t, err)
+	assert.True(t, status.Repo)
+	assert.NoFileExists(t, marker)
+}
+
+// countingReader adalah GitStatusReader palsu yang mencatat path yang
+// dibaca. Jika gate terisi, pembacaan menunggu sampai gate ditutup.
+type countingReader struct {
+	calls atomic.Int32
+	gate  chan struct{}
+	err   error
+}
+
+func (r *countingReader) read(ctx context.Context, dir string) (*models.GitStatus, error) {
+	n := r.calls.Add(1)
+	if r.gate != nil {
+		<-r.gate
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &models.GitStatus{Repo: true, Branch: fmt.Sprintf("baca-%d", n), CheckedAt: time.Now()}, nil
+}
+
+func newGitStatusFixture(t *testing.T, opts ...services.GitStatusOption) (services.GitStatusService, uint, repositories.TaskRepository) {
+	t.Helper()
+	repo := repositories.NewTaskRepository(newIsolatedDB(t))
+	service := services.NewTaskService(repo, t.TempDir())
+	dir := t.TempDir()
+	task, err := repo.Create(&models.Task{Judul: "Proyek", Tipe: "Project Local", Status: "todo", PathProject: &dir})
+	require.NoError(t, err)
+	return services.NewGitStatusService(service, opts...), task.ID, repo
+}
+
+func TestGitStatusServiceCachesAndRefreshes(t *testing.T) {
+	reader := &countingReader{}
+	gitStatus, id, _ := newGitStatusFixture(t, services.WithGitStatusReader(reader.read))
+
+	first, err := gitStatus.Status(ctx, id)
+	require.NoError(t, err)
+	second, err := gitStatus.Status(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "baca-1", first.Branch)
+	assert.Equal(t, first, second, "status kedua dari cache")
+	assert.EqualValues(t, 1, reader.calls.Load())
+
+	// Pembaruan latar belakang membaca ulang path yang sedang dilihat.
+	gitStatus.Refresh(ctx)
+	assert.EqualValues(t, 2, reader.calls.Load())
+	refreshed, err := gitStatus.Status(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "baca-2", refreshed.Branch)
+	assert.EqualValues(t, 2, reader.calls.Load())
+
+	_, err = gitStatus.Status(ctx, 999)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+}
+
+func TestGitStatusServiceExpiresEntries(t *testing.T) {
+	reader := &countingReader{}
+	gitStatus, id, _ := newGitStatusFixture(t, services.WithGitStatusReader(reader.read),
+		services.WithGitStatusTTL(time.Nanosecond), services.WithGitStatusIdle(time.Nanosecond))
+
+	_, err := gitStatus.Status(ctx, id)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	// Path yang sudah tidak dilihat dibuang, bukan dibaca ulang.
+	gitStatus.Refresh(ctx)
+	assert.EqualValues(t, 1, reader.calls.Load())
+	// Cache yang kedaluwarsa dibaca ulang saat diminta.
+	status, err := gitStatus.Status(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "baca-2", status.Branch)
+}
+
+func TestGitStatusServiceSharesInFlightRead(t *testing.T) {
+	reader := &countingReader{gate: make(chan struct{})}
+	gitStatus, id, _ := newGitStatusFixture(t, services.WithGitStatusReader(reader.read))
+
+	var wg sync.WaitGroup
+	results := make([]*models.GitStatus, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			status, err := gitStatus.Status(ctx, id)
+			assert.NoError(t, err)
+			results[i] = status
+		}()
+	}
+	require.Eventually(t, func() bool { return reader.calls.Load() == 1 }, time.Second, time.Millisecond)
+	close(reader.gate)
+	wg.Wait()
+	assert.EqualValues(t, 1, reader.calls.Load())
+	for _, status := range results {
+		require.NotNil(t, status)
+		assert.Equal(t, "baca-1", status.Branch)
+	}
+}
+
+// Request yang dibatalkan berhenti menunggu git yang lambat, dan hasil git
+// tetap tersimpan untuk request berikutnya.
+func TestGitStatusServiceHonoursCancellation(t *testing.T) {
+	reader := &countingReader{gate: make(chan struct{})}
+	gitStatus, id, _ := newGitStatusFixture(t, services.WithGitStatusReader(reader.read))
+
+	reqCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error, 1)
+	go func() {
+		_, err := gitStatus.Status(reqCtx, id)
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return reader.calls.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("Status tidak berhenti setelah context dibatalkan")
+	}
+
+	close(reader.gate)
+	status, err := gitStatus.Status(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "baca-1", status.Branch)
+	assert.EqualValues(t, 1, reader.calls.Load())
+}
+
+func TestGitStatusServiceCachesErrors(t *testing.T) {
+	reader := &countingReader{err: errors.New("git rusak")}
+	gitStatus, id, _ := newGitStatusFixture(t, services.WithGitStatusReader(reader.read))
+
+	for range 2 {
+		_, err := gitStatus.Status(ctx, id)
+		assert.EqualError(t, err, "git rusak")
+	}
+	assert.EqualValues(t, 1, reader.calls.Load(), "git yang gagal tidak dijalankan ulang sebelum TTL habis")
+}
+
+func TestAPIGitStatus(t *testing.T) {
+	requireGit(t)
+	router, repo := newTestRouter(t)
+	dir := t.TempDir()
+	git(t, dir, "init", "-q", "-b", "utama")
+	writeFile(t, filepath.Join(dir, "a.txt"), "a\n")
+	git(t, dir, "add", "a.txt")
+	git(t, dir, "commit", "-q", "-m", "Mulai")
+	task, err := repo.Create(&models.Task{Judul: "Proyek", Tipe: "Project Local", Status: "todo", PathProject: &dir})
+	require.NoError(t, err)
+	noPath, err := repo.Create(&models.Task{Judul: "Tanpa path", Tipe: "Project Local", Status: "todo"})
+	require.NoError(t, err)
+
+	rec := doJSON(t, router, http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d/git", task.ID), nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var body struct {
+		Data models.GitStatus `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.True(t, body.Data.Repo)
+	assert.Equal(t, "utama", body.Data.Branch)
+	require.NotNil(t, body.Data.LastCommit)
+	assert.Equal(t, "Mulai", body.Data.LastCommit.Message)
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantErr  string
+	}{
+		{fmt.Sprintf("/api/v1/tasks/%d/git", noPath.ID), http.StatusUnprocessableEntity, "no_project_path"},
+		{"/api/v1/tasks/999/git", http.StatusNotFound, "not_found"},
+	}
+	for _, tc := range tests {
+		rec := doJSON(t, router, http.MethodGet, tc.path, nil)
+		assert.Equal(t, tc.wantCode, rec.Code, tc.path)
+		var errBody apiErrorBody
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &errBody))
+		assert.Equal(t, tc.wantErr, errBody.Error.Code)
+	}
+}
+
+func TestTaskCardHasGitBadgeForLocalProjects(t *testing.T) {
+	srv := newWebSocketServer(t)
+	dir := t.TempDir()
+	local, err := srv.repo.Create(&models.Task{Judul: "Lokal", Tipe: "Project Local", Status: "todo", PathProject: &dir})
+	require.NoError(t, err)
+	website, err := srv.repo.Create(&models.Task{Judul: "Situs", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+
+	resp := srv.do(t, http.MethodGet, "/", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	page := string(body)
+	assert.Contains(t, page, fmt.Sprintf(`data-git-task-id="%d"`, local.ID))
+	assert.NotContains(t, page, fmt.Sprintf(`data-git-task-id="%d"`, website.ID))
+}
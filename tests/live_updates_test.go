@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+type recordingNotifier struct {
+	changes []services.TaskChange
+}
+
+func (n *recordingNotifier) TaskChanged(change services.TaskChange) {
+	n.changes = append(n.changes, change)
+}
+
+func TestTaskServiceNotifiesCommittedChanges(t *testing.T) {
+	db := newIsolatedDB(t)
+	notifier := &recordingNotifier{}
+	service := services.NewTaskService(repositories.NewTaskRepository(db), t.TempDir(), services.WithNotifier(notifier))
+	auth := services.NewAuthService(repositories.NewUserRepository(db))
+	user, err := auth.Register(ctx, "budi", "rahasia123")
+	require.NoError(t, err)
+	userCtx := services.WithActor(services.WithUser(ctx, user), "budi")
+
+	task, err := service.CreateTask(userCtx, &models.Task{Judul: "Baru", Tipe: "Website"}, nil)
+	require.NoError(t, err)
+	_, err = service.UpdateTask(userCtx, task.ID, &models.Task{Judul: "Diubah", Status: "inprogress"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTask(userCtx, task.ID))
+	_, err = service.RestoreTask(userCtx, task.ID)
+	require.NoError(t, err)
+
+	// Perubahan yang gagal tidak dikirim.
+	_, err = service.UpdateTask(userCtx, 999, &models.Task{Judul: "x"}, nil)
+	require.Error(t, err)
+	_, err = service.UpdateTask(userCtx, task.ID, &models.Task{Status: "tidak-ada"}, nil)
+	require.Error(t, err)
+
+	require.Len(t, notifier.changes, 4)
+	actions := []string{}
+	for _, change := range notifier.changes {
+		actions = append(actions, change.Action)
+		assert.Equal(t, task.ID, change.TaskID)
+		assert.Equal(t, user.ID, change.OwnerID)
+		assert.Equal(t, "budi", change.Actor)
+	}
+	assert.Equal(t, []string{services.TaskCreated, services.TaskUpdated, services.TaskDeleted, services.TaskCreated}, actions)
+	assert.Equal(t, "Diubah", notifier.changes[1].Task.Judul)
+	assert.Nil(t, notifier.changes[2].Task, "task yang dihapus tidak dikirim")
+}
+
+type taskEventMessage struct {
+	Type string `json:"type"`
+	Data struct {
+		Action string       `json:"action"`
+		TaskID uint         `json:"task_id"`
+		Actor  string       `json:"actor"`
+		Task   *models.Task `json:"task"`
+	} `json:"data"`
+}
+
+func readTaskEvent(t *testing.T, conn *websocket.Conn) taskEventMessage {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	var msg taskEventMessage
+	require.NoError(t, json.Unmarshal(data, &msg))
+	require.Equal(t, "task_event", msg.Type, "%s", data)
+	return msg
+}
+
+func TestTaskChangesBroadcastToOwnerTabs(t *testing.T) {
+	srv := newWebSocketServer(t)
+	tabA, tabB := srv.dial(t), srv.dial(t)
+	otherUser := srv.dialAs(t, srv.otherHeader)
+
+	resp := srv.do(t, http.MethodPost, "/api/v1/tasks", map[string]any{"judul": "Live", "tipe": "Website"})
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	var created apiTaskResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&created))
+	id := created.Data.ID
+
+	for _, tab := range []*websocket.Conn{tabA, tabB} {
+		msg := readTaskEvent(t, tab)
+		assert.Equal(t, "created", msg.Data.Action)
+		assert.Equal(t, id, msg.Data.TaskID)
+		assert.Equal(t, "tester", msg.Data.Actor)
+		require.NotNil(t, msg.Data.Task)
+		assert.Equal(t, "Live", msg.Data.Task.Judul)
+	}
+
+	resp = srv.do(t, http.MethodPut, fmt.Sprintf("/api/v1/tasks/%d", id), map[string]any{"judul": "Live 2", "tipe": "Website"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	msg := readTaskEvent(t, tabB)
+	assert.Equal(t, "updated", msg.Data.Action)
+	assert.Equal(t, "Live 2", msg.Data.Task.Judul)
+
+	resp = srv.do(t, http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d", id), nil)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+	msg = readTaskEvent(t, tabB)
+	assert.Equal(t, "deleted", msg.Data.Action)
+	assert.Equal(t, id, msg.Data.TaskID)
+	assert.Nil(t, msg.Data.Task)
+
+	// Event ke pengguna lain pasti tiba sebelum balasan perintah ini, jadi
+	// balasan pertama yang diterima membuktikan tidak ada event yang bocor.
+	reply := sendCommand(t, otherUser, "cek", "open_terminal", map[string]any{"terminal": "kitty", "task_id": id})
+	assert.Equal(t, "error", reply.Type)
+}
+
+func TestTaskCardPartial(t *testing.T) {
+	srv := newWebSocketServer(t)
+	task, err := srv.repo.Create(&models.Task{Judul: "Kartu <b>", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	foreign, err := srv.otherRepo.Create(&models.Task{Judul: "Milik lain", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+
+	resp := srv.do(t, http.MethodGet, fmt.Sprintf("/task/card/%d", task.ID), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), fmt.Sprintf(`data-task-id="%d"`, task.ID))
+	assert.Contains(t, string(body), "Kartu &lt;b&gt;")
+	assert.NotContains(t, string(body), "<html")
+
+	assert.Equal(t, http.StatusNotFound, srv.do(t, http.MethodGet, fmt.Sprintf("/task/card/%d", foreign.ID), nil).StatusCode)
+	assert.Equal(t, http.StatusBadRequest, srv.do(t, http.MethodGet, "/task/card/abc", nil).StatusCode)
+}
@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/nabilulilalbab/welcomesite/config"
+	"github.com/nabilulilalbab/welcomesite/migrations"
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+)
+
+// newEmptyDB membuat database in-memory tanpa tabel apa pun.
+func newEmptyDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// assertSchemaMatchesModels memastikan setiap kolom yang dipakai model ada
+// di database hasil migrasi.
+func assertSchemaMatchesModels(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	for _, model := range []any{&models.Task{}, &models.Tag{}, &models.TaskEvent{}, &models.User{}, &models.Session{}, &models.CoverVariant{}} {
+		stmt := &gorm.Statement{DB: db}
+		require.NoError(t, stmt.Parse(model))
+		require.True(t, db.Migrator().HasTable(model), stmt.Schema.Table)
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" || field.IgnoreMigration {
+				continue
+			}
+			assert.True(t, db.Migrator().HasColumn(model, field.DBName), "%s.%s", stmt.Schema.Table, field.DBName)
+		}
+	}
+	assert.True(t, db.Migrator().HasTable("task_tags"))
+	assert.True(t, db.Migrator().HasIndex(&models.Task{}, "DeletedAt"))
+	assert.True(t, db.Migrator().HasIndex(&models.Task{}, "OwnerID"))
+	assert.True(t, db.Migrator().HasIndex(&models.Tag{}, "idx_tags_owner_name"))
+}
+
+func TestMigrationsUpMatchModels(t *testing.T) {
+	db := newEmptyDB(t)
+	runner := migrations.New(db)
+
+	applied, err := runner.Up()
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrations.All))
+	assertSchemaMatchesModels(t, db)
+
+	current, err := runner.Current()
+	require.NoError(t, err)
+	assert.Equal(t, runner.Latest(), current)
+	pending, err := runner.Pending()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+// Database yang dibuat AutoMigrate sebelum ada sistem migrasi harus bisa
+// diadopsi tanpa kehilangan data.
+func TestMigrationsAdoptAutoMigratedDatabase(t *testing.T) {
+	db := newEmptyDB(t)
+	require.NoError(t, db.AutoMigrate(&models.Task{}, &models.Tag{}, &models.TaskEvent{}))
+	require.NoError(t, db.Create(&models.Task{Judul: "Lama", Status: "todo", Tipe: "Website"}).Error)
+
+	_, err := migrations.New(db).Up()
+	require.NoError(t, err)
+	var count int64
+	require.NoError(t, db.Model(&models.Task{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestMigrationsDownAndBackUp(t *testing.T) {
+	db := newEmptyDB(t)
+	runner := migrations.New(db)
+	_, err := runner.Up()
+	require.NoError(t, err)
+
+	task := models.Task{Judul: "A", Status: "todo", Tipe: "Website"}
+	require.NoError(t, db.Create(&task).Error)
+	require.NoError(t, db.Exec("INSERT INTO tags (name, created_at) VALUES ('go', CURRENT_TIMESTAMP), ('api', CURRENT_TIMESTAMP)").Error)
+	require.NoError(t, db.Exec("INSERT INTO task_tags (task_id, tag_id) SELECT ?, id FROM tags", task.ID).Error)
+
+	reverted, err := runner.Down()
+	require.NoError(t, err)
+	require.NotNil(t, reverted)
+	assert.Equal(t, runner.Latest(), reverted.Version)
+	_, err = runner.To(3)
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasColumn("tasks", "deleted_at"))
+	assert.False(t, db.Migrator().HasTable("users"))
+
+	// Kembali ke versi 1: tag dikembalikan ke kolom teks.
+	_, err = runner.To(1)
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable("tags"))
+	assert.False(t, db.Migrator().HasTable("task_events"))
+	var legacy string
+	require.NoError(t, db.Table("tasks").Select("tags").Where("id = ?", task.ID).Scan(&legacy).Error)
+	assert.Equal(t, "api, go", legacy)
+
+	statuses, err := runner.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, len(migrations.All))
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.Nil(t, statuses[1].AppliedAt)
+
+	_, err = runner.Up()
+	require.NoError(t, err)
+	assertSchemaMatchesModels(t, db)
+	var restored models.Task
+	require.NoError(t, db.Preload("Tags").First(&restored, task.ID).Error)
+	assert.ElementsMatch(t, []string{"go", "api"}, models.TagNames(restored.Tags))
+
+	_, err = runner.To(0)
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasTable("tasks"))
+	reverted, err = runner.Down()
+	require.NoError(t, err)
+	assert.Nil(t, reverted)
+
+	_, err = runner.To(99)
+	assert.ErrorIs(t, err, migrations.ErrUnknownVersion)
+}
+
+// Tag yang dipakai task beberapa pengguna dipecah menjadi tag per pemilik,
+// dan digabung kembali saat migrasi dibatalkan.
+func TestMigrationsSplitSharedTags(t *testing.T) {
+	db := newEmptyDB(t)
+	runner := migrations.New(db)
+	_, err := runner.To(8)
+	require.NoError(t, err)
+
+	for _, stmt := range []string{
+		"INSERT INTO users (id, username, password_hash, created_at) VALUES (1, 'alice', 'x', CURRENT_TIMESTAMP), (2, 'bob', 'x', CURRENT_TIMESTAMP)",
+		"INSERT INTO tasks (id, judul, status, tipe, owner_id) VALUES (1, 'A', 'todo', 'Website', 1), (2, 'B', 'todo', 'Website', 2), (3, 'C', 'todo', 'Website', 2)",
+		"UPDATE tasks SET deleted_at = CURRENT_TIMESTAMP WHERE id = 3",
+		"INSERT INTO tags (id, name, created_at) VALUES (1, 'go', CURRENT_TIMESTAMP), (2, 'api', CURRENT_TIMESTAMP), (3, 'lama', CURRENT_TIMESTAMP)",
+		"INSERT INTO task_tags (task_id, tag_id) VALUES (1, 1), (2, 1), (3, 1), (3, 2)",
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	_, err = runner.Up()
+	require.NoError(t, err)
+	tagsOf := func(userID uint) map[string]int64 {
+		t.Helper()
+		tags, err := repositories.NewTagRepository(db).WithOwner(userID).FindAllWithCount()
+		require.NoError(t, err)
+		result := map[string]int64{}
+		for _, tag := range tags {
+			result[tag.Name] = tag.TaskCount
+		}
+		return result
+	}
+	assert.Equal(t, map[string]int64{"go": 1}, tagsOf(1))
+	assert.Equal(t, map[string]int64{"go": 1, "api": 0}, tagsOf(2))
+	var orphan models.Tag
+	require.NoError(t, db.First(&orphan, 3).Error)
+	assert.Zero(t, orphan.OwnerID, "tag yang tidak dipakai belum punya pemilik")
+	var trashed models.Task
+	require.NoError(t, db.Unscoped().Preload("Tags").First(&trashed, 3).Error)
+	assert.ElementsMatch(t, []string{"go", "api"}, models.TagNames(trashed.Tags))
+	assert.Equal(t, uint(2), trashed.Tags[0].OwnerID)
+
+	_, err = runner.Down()
+	require.NoError(t, err)
+	var count int64
+	require.NoError(t, db.Table("tags").Where("name = ?", "go").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+	require.NoError(t, db.Table("task_tags").Where("tag_id = ?", 1).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestMigrationsRefuseNewerSchema(t *testing.T) {
+	db := newEmptyDB(t)
+	runner := migrations.New(db)
+	_, err := runner.Up()
+	require.NoError(t, err)
+	require.NoError(t, db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (999, 'dari_masa_depan', CURRENT_TIMESTAMP)").Error)
+
+	assert.ErrorIs(t, runner.Check(), migrations.ErrSchemaTooNew)
+	_, err = runner.Up()
+	assert.ErrorIs(t, err, migrations.ErrSchemaTooNew)
+	_, err = runner.Down()
+	assert.ErrorIs(t, err, migrations.ErrSchemaTooNew)
+
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	err = config.InitDatabase(config.DatabaseConfig{DSN: dsn, AutoMigrate: true})
+	assert.ErrorIs(t, err, migrations.ErrSchemaTooNew)
+}
+
+func TestInitDatabaseWithoutAutoMigrate(t *testing.T) {
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	keepAlive := newEmptyDB(t) // menjaga database in-memory tetap ada
+
+	err := config.InitDatabase(config.DatabaseConfig{DSN: dsn})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "migrate up")
+
+	_, err = migrations.New(keepAlive).Up()
+	require.NoError(t, err)
+	require.NoError(t, config.InitDatabase(config.DatabaseConfig{DSN: dsn}))
+}
+
+func TestMigrateCommand(t *testing.T) {
+	runner := migrations.New(newEmptyDB(t))
+	run := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		err := migrations.RunCommand(runner, args, &out)
+		return out.String(), err
+	}
+
+	out, err := run("status")
+	require.NoError(t, err)
+	assert.Regexp(t, `0001\s+create_tasks\s+tertunda`, out)
+
+	out, err = run("to", "2")
+	require.NoError(t, err)
+	assert.Equal(t, "0001 create_tasks diproses\n0002 create_tags diproses\n", out)
+
+	out, err = run("up")
+	require.NoError(t, err)
+	assert.Contains(t, out, "0006 add_task_owner dijalankan")
+	out, err = run("up")
+	require.NoError(t, err)
+	assert.Contains(t, out, "tidak ada migrasi")
+
+	out, err = run("down")
+	require.NoError(t, err)
+	assert.Equal(t, "0009 add_tag_owner dibatalkan\n", out)
+
+	for _, args := range [][]string{nil, {"sideways"}, {"to"}, {"to", "x"}, {"up", "extra"}} {
+		_, err := run(args...)
+		assert.Error(t, err, "%q", args)
+	}
+}
@@ -1,10 +1,13 @@
 package mock
 
 import (
+	"time"
+
 	"github.com/stretchr/testify/mock"
 	"gorm.io/gorm"
 
 	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
 )
 
 type MockRepository struct {
@@ -26,6 +29,11 @@ func (m *MockRepository) FindAll() ([]models.Task, error) {
 	return args.Get(0).([]models.Task), args.Error(1)
 }
 
+func (m *MockRepository) FindByQuery(query models.TaskQuery) (*models.TaskPage, error) {
+	args := m.Called(query)
+	return args.Get(0).(*models.TaskPage), args.Error(1)
+}
+
 func (m *MockRepository) Update(task *models.Task) (*models.Task, error) {
 	args := m.Called(task)
 	return args.Get(0).(*models.Task), args.Error(1)
@@ -40,3 +48,68 @@ func (m *MockRepository) GetDB() *gorm.DB {
 	args := m.Called()
 	return args.Get(0).(*gorm.DB)
 }
+
+func (m *MockRepository) FindByIDWithTx(id uint, tx *gorm.DB) (*models.Task, error) {
+	args := m.Called(id, tx)
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockRepository) ReplaceTagsWithTx(task *models.Task, names []string, tx *gorm.DB) error {
+	args := m.Called(task, names, tx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) HasCoverKey(key string) (bool, error) {
+	args := m.Called(key)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) ReplaceCoverVariantsWithTx(task *models.Task, variants []models.CoverVariant, tx *gorm.DB) error {
+	args := m.Called(task, variants, tx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) DeleteWithTx(id uint, tx *gorm.DB) error {
+	args := m.Called(id, tx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) FindEvents(taskID uint) ([]models.TaskEvent, error) {
+	args := m.Called(taskID)
+	return args.Get(0).([]models.TaskEvent), args.Error(1)
+}
+
+func (m *MockRepository) FindTrash() ([]models.Task, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockRepository) FindTrashedByID(id uint) (*models.Task, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.Task), args.Error(1)
+}
+
+func (m *MockRepository) FindTrashedBefore(cutoff time.Time) ([]models.Task, error) {
+	args := m.Called(cutoff)
+	return args.Get(0).([]models.Task), args.Error(1)
+}
+
+func (m *MockRepository) RestoreWithTx(id uint, tx *gorm.DB) error {
+	args := m.Called(id, tx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) PurgeWithTx(id uint, tx *gorm.DB) error {
+	args := m.Called(id, tx)
+	return args.Error(0)
+}
+
+func (m *MockRepository) WithOwner(ownerID uint) repositories.TaskRepository {
+	args := m.Called(ownerID)
+	return args.Get(0).(repositories.TaskRepository)
+}
+
+func (m *MockRepository) CreateWithTx(task *models.Task, tx *gorm.DB) error {
+	args := m.Called(task, tx)
+	return args.Error(0)
+}
@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+func newCoverFixture(t *testing.T) (services.TaskService, *gorm.DB, string, *models.Task) {
+	t.Helper()
+	db := newIsolatedDB(t)
+	uploads := t.TempDir()
+	service := services.NewTaskService(repositories.NewTaskRepository(db), uploads)
+	cover := createMultipartFileHeader(t, "cover", "cover.jpg", encodeJPEG(t, 40, 20))
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"}, cover)
+	require.NoError(t, err)
+	require.NotEmpty(t, task.Cover)
+	return service, db, filepath.Join(uploads, filepath.Base(task.Cover)), task
+}
+
+func TestRemoveCover(t *testing.T) {
+	service, _, coverFile, task := newCoverFixture(t)
+	require.FileExists(t, coverFile)
+
+	updated, err := service.RemoveCover(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "", updated.Cover)
+	assert.NoFileExists(t, coverFile)
+
+	stored, err := service.GetTaskByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "", stored.Cover)
+
+	events, err := service.GetTaskEvents(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskEventCover, events[0].Operation)
+	assert.Equal(t, models.FieldChanges{{Field: "cover", Old: task.Cover, New: ""}}, events[0].Changes)
+
+	// Task tanpa cover tidak berubah dan tidak menambah event.
+	_, err = service.RemoveCover(ctx, task.ID)
+	require.NoError(t, err)
+	again, err := service.GetTaskEvents(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Len(t, again, len(events))
+
+	_, err = service.RemoveCover(ctx, 999)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+}
+
+func TestRemoveCoverKeepsFileWhenUpdateFails(t *testing.T) {
+	service, db, coverFile, task := newCoverFixture(t)
+	require.NoError(t, db.Callback().Update().Before("gorm:update").Register("test:gagal", func(tx *gorm.DB) {
+		tx.AddError(errors.New("update gagal"))
+	}))
+
+	_, err := service.RemoveCover(ctx, task.ID)
+	require.Error(t, err)
+	assert.FileExists(t, coverFile)
+
+	require.NoError(t, db.Callback().Update().Remove("test:gagal"))
+	stored, err := service.GetTaskByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Cover, stored.Cover)
+}
+
+func TestRemoveCoverRoutes(t *testing.T) {
+	router, _ := newTestRouter(t)
+	create := func() models.Task {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/api/v1/tasks", encodeJPEG(t, 40, 20)))
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var resp apiTaskResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.NotEmpty(t, resp.Data.Cover)
+		return resp.Data
+	}
+
+	task := create()
+	rec := doJSON(t, router, http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d/cover", task.ID), nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "", resp.Data.Cover)
+	assert.Equal(t, task.Judul, resp.Data.Judul)
+
+	rec = doJSON(t, router, http.MethodDelete, "/api/v1/tasks/999/cover", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	task = create()
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/task/cover/delete/%d", task.ID), nil))
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	rec = doJSON(t, router, http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d", task.ID), nil)
+	resp = apiTaskResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "", resp.Data.Cover)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/task/cover/delete/999", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestCardExposesCoverForEditModal(t *testing.T) {
+	router, _ := newTestRouter(t)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/api/v1/tasks", encodeJPEG(t, 40, 20)))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var resp apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/task/card/%d", resp.Data.ID), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf(`data-cover="%s"`, resp.Data.Cover))
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite"
+	"github.com/nabilulilalbab/welcomesite/config"
+	"github.com/nabilulilalbab/welcomesite/controllers"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/routes"
+	"github.com/nabilulilalbab/welcomesite/server"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/view"
+)
+
+// startServer menjalankan server.Serve di goroutine dan mengembalikan
+// alamatnya serta channel hasil Serve.
+func startServer(t *testing.T, ctx context.Context, handler http.Handler, timeout time.Duration, drainers ...server.Drainer) (string, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := server.New(config.Default().Server, handler)
+	result := make(chan error, 1)
+	go func() {
+		result <- server.Serve(ctx, srv, ln, timeout, drainers...)
+	}()
+	return ln.Addr().String(), result
+}
+
+func TestNewServerUsesConfiguredTimeouts(t *testing.T) {
+	cfg := config.ServerConfig{
+		Addr: ":1234", ReadTimeout: 3 * time.Second, WriteTimeout: 4 * time.Second,
+		IdleTimeout: 5 * time.Second, ShutdownTimeout: time.Second,
+	}
+	srv := server.New(cfg, http.NotFoundHandler())
+	assert.Equal(t, ":1234", srv.Addr)
+	assert.Equal(t, 3*time.Second, srv.ReadTimeout)
+	assert.Equal(t, 3*time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, 4*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 5*time.Second, srv.IdleTimeout)
+}
+
+func TestServeDrainsInFlightRequests(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "selesai")
+	})
+	var drained []string
+	drainer := func(name string) server.Drainer {
+		return server.Drainer{Name: name, Drain: func(context.Context) error {
+			drained = append(drained, name)
+			return nil
+		}}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, result := startServer(t, ctx, handler, 5*time.Second, drainer("websocket"), drainer("database"))
+
+	response := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + addr)
+		if err != nil {
+			response <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		response <- string(body)
+	}()
+	<-started
+	cancel()
+
+	// Server berhenti menerima koneksi baru tetapi tetap menunggu request
+	// yang sedang berjalan.
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case err := <-result:
+		t.Fatalf("Serve selesai sebelum request selesai: %v", err)
+	default:
+	}
+	assert.Empty(t, drained, "drainer dijalankan setelah request HTTP selesai")
+
+	close(release)
+	assert.Equal(t, "selesai", <-response)
+	require.NoError(t, <-result)
+	assert.Equal(t, []string{"websocket", "database"}, drained)
+}
+
+func TestServeGivesUpAfterShutdownTimeout(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, result := startServer(t, ctx, handler, 50*time.Millisecond)
+
+	go http.Get("http://" + addr)
+	<-started
+	cancel()
+	select {
+	case err := <-result:
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Serve tidak menghormati shutdown timeout")
+	}
+}
+
+func TestShutdownClosesWebSockets(t *testing.T) {
+	repo := repositories.NewTaskRepository(newIsolatedDB(t))
+	service := services.NewTaskService(repo, t.TempDir())
+	taskCtrl := controllers.NewTaskController(service, view.ParseTemplates())
+	auth := services.NewAuthService(repositories.NewUserRepository(repo.GetDB()))
+	router := routes.NewRouter(taskCtrl, controllers.NewTaskAPIController(service),
+		controllers.NewTagAPIController(services.NewTagService(repositories.NewTagRepository(repo.GetDB()))),
+		controllers.NewAuthController(auth, view.ParseTemplates(), false),
+		welcomesite.StaticFS)
+	_, token := loginTestUser(t, auth, "tester")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	addr, result := startServer(t, ctx, router, 5*time.Second,
+		server.Drainer{Name: "websocket", Drain: taskCtrl.CloseWebSockets})
+
+	header := http.Header{"Cookie": {controllers.SessionCookieName + "=" + token}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/ws", header)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	cancel()
+	// Klien menerima close frame "going away" lalu membalasnya (dilakukan
+	// otomatis oleh gorilla saat ReadMessage).
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "%v", err)
+	require.NoError(t, <-result)
+}
@@ -0,0 +1,383 @@
+package tests
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/storage"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	local := storage.NewLocal(dir, "/static/uploads/tasks/")
+
+	require.NoError(t, local.Put(ctx, "a.jpg", strings.NewReader("isi"), "image/jpeg"))
+	rc, err := local.Get(ctx, "a.jpg")
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "isi", string(data))
+	assert.Equal(t, "/static/uploads/tasks/a.jpg", local.URL("a.jpg"))
+	assert.Equal(t, "a.jpg", storage.KeyFromURL(local.URL("a.jpg")))
+
+	require.NoError(t, local.Delete(ctx, "a.jpg"))
+	_, err = local.Get(ctx, "a.jpg")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.NoError(t, local.Delete(ctx, "a.jpg"), "key yang tidak ada bukan error")
+
+	for _, key := range []string{"", "..", "../a.jpg", "sub/a.jpg", `sub\a.jpg`} {
+		assert.ErrorIs(t, local.Put(ctx, key, strings.NewReader("x"), ""), storage.ErrInvalidKey, key)
+		_, err := local.Get(ctx, key)
+		assert.ErrorIs(t, err, storage.ErrInvalidKey, key)
+	}
+	assert.Empty(t, dirNames(t, dir))
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+}
+
+// fakeS3 meniru subset API S3 yang dipakai storage.S3 (PUT, GET, DELETE
+// object dengan path-style dan ListObjectsV2) dan menolak request yang tanda tangan SigV4-nya
+// tidak cocok, seperti MinIO.
+type fakeS3 struct {
+	bucket, region, accessKey, secretKey string
+
+	mu       sync.Mutex
+	objects  map[string]fakeObject
+	requests int
+	rejected int
+	// failAt membuat request ke-failAt dijawab 500.
+	failAt int
+	// pageSize membatasi jumlah key per halaman ListObjectsV2.
+	pageSize int
+}
+
+// failIn membuat request ke-n dari sekarang gagal.
+func (f *fakeS3) failIn(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failAt = f.requests + n
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	t.Helper()
+	fake := &fakeS3{
+		bucket:    "covers",
+		region:    "us-east-1",
+		accessKey: "minioadmin",
+		secretKey: "rahasia/+=",
+		objects:   map[string]fakeObject{},
+		pageSize:  1000,
+	}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return fake, srv
+}
+
+func (f *fakeS3) config(endpoint string) storage.S3Config {
+	return storage.S3Config{
+		Endpoint:        endpoint,
+		Region:          f.region,
+		Bucket:          f.bucket,
+		AccessKeyID:     f.accessKey,
+		SecretAccessKey: f.secretKey,
+	}
+}
+
+func (f *fakeS3) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.objects))
+	for k := range f.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.verify(r, body); err != nil {
+		f.rejected++
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprintf(w, "<Error><Code>SignatureDoesNotMatch</Code><Message>%s</Message></Error>", err)
+		return
+	}
+	f.requests++
+	if f.requests == f.failAt {
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, "<Error><Code>InternalError</Code></Error>")
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket+"/")
+	if ok && key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+		f.list(w, r.URL.Query().Get("continuation-token"))
+		return
+	}
+	if !ok || key == "" {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "<Error><Code>NoSuchBucket</Code></Error>")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = fakeObject{data: body, contentType: r.Header.Get("Content-Type"), modTime: time.Now().UTC().Truncate(time.Second)}
+	case http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Header().Set("Last-Modified", obj.modTime.Format(http.TimeFormat))
+		w.Write(obj.data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// list menjawab ListObjectsV2 per pageSize key, dengan key terakhir
+// halaman sebagai continuation token.
+func (f *fakeS3) list(w http.ResponseWriter, after string) {
+	keys := make([]string, 0, len(f.objects))
+	for k := range f.objects {
+		if k > after {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	truncated := len(keys) > f.pageSize
+	if truncated {
+		keys = keys[:f.pageSize]
+	}
+	fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	fmt.Fprintf(w, "<Name>%s</Name><KeyCount>%d</KeyCount>", f.bucket, len(keys))
+	for _, k := range keys {
+		obj := f.objects[k]
+		fmt.Fprintf(w, "<Contents><Key>%s</Key><LastModified>%s</LastModified><Size>%d</Size></Contents>",
+			k, obj.modTime.Format("2006-01-02T15:04:05.000Z"), len(obj.data))
+	}
+	fmt.Fprintf(w, "<IsTruncated>%t</IsTruncated>", truncated)
+	if truncated {
+		fmt.Fprintf(w, "<NextContinuationToken>%s</NextContinuationToken>", keys[len(keys)-1])
+	}
+	fmt.Fprint(w, "</ListBucketResult>")
+}
+
+// verify menghitung ulang tanda tangan AWS Signature Version 4 dari request
+// yang diterima.
+func (f *fakeS3) verify(r *http.Request, body []byte) error {
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return errors.New("authorization bukan AWS4-HMAC-SHA256")
+	}
+	fields := map[string]string{}
+	for _, part := range strings.Split(auth, ", ") {
+		k, v, _ := strings.Cut(part, "=")
+		fields[k] = v
+	}
+	amzDate := r.Header.Get("X-Amz-Date")
+	if len(amzDate) != 16 {
+		return errors.New("x-amz-date tidak ada")
+	}
+	scope := amzDate[:8] + "/" + f.region + "/s3/aws4_request"
+	if fields["Credential"] != f.accessKey+"/"+scope {
+		return fmt.Errorf("credential %q salah", fields["Credential"])
+	}
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		return errors.New("hash payload tidak cocok")
+	}
+
+	signedHeaders := strings.Split(fields["SignedHeaders"], ";")
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	if !strings.Contains(fields["SignedHeaders"], "host") || !strings.Contains(fields["SignedHeaders"], "x-amz-date") {
+		return errors.New("host dan x-amz-date harus ditandatangani")
+	}
+	query := r.URL.Query()
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var pairs []string
+	for _, name := range names {
+		for _, v := range query[name] {
+			pairs = append(pairs, url.QueryEscape(name)+"="+strings.ReplaceAll(url.QueryEscape(v), "+", "%20"))
+		}
+	}
+	canonical := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), strings.Join(pairs, "&"),
+		canonicalHeaders.String(), fields["SignedHeaders"], r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	hashed := sha256.Sum256([]byte(canonical))
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	key := mac([]byte("AWS4"+f.secretKey), amzDate[:8])
+	for _, part := range []string{f.region, "s3", "aws4_request"} {
+		key = mac(key, part)
+	}
+	if want := hex.EncodeToString(mac(key, toSign)); fields["Signature"] != want {
+		return errors.New("signature tidak cocok")
+	}
+	return nil
+}
+
+func TestS3Storage(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	s3, err := storage.NewS3(fake.config(srv.URL), services.CoverURLPrefix)
+	require.NoError(t, err)
+
+	require.NoError(t, s3.Put(ctx, "task_1_full.jpg", bytes.NewReader([]byte("jpeg")), "image/jpeg"))
+	assert.Equal(t, []string{"task_1_full.jpg"}, fake.keys())
+	assert.Equal(t, "image/jpeg", fake.objects["task_1_full.jpg"].contentType)
+
+	rc, err := s3.Get(ctx, "task_1_full.jpg")
+	require.NoError(t, err)
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", string(data))
+	assert.Equal(t, "/static/uploads/tasks/task_1_full.jpg", s3.URL("task_1_full.jpg"))
+
+	_, err = s3.Get(ctx, "tidak-ada.jpg")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	require.NoError(t, s3.Delete(ctx, "task_1_full.jpg"))
+	assert.Empty(t, fake.keys())
+	assert.NoError(t, s3.Delete(ctx, "task_1_full.jpg"))
+
+	fake.failIn(1)
+	err = s3.Put(ctx, "b.jpg", strings.NewReader("x"), "image/jpeg")
+	assert.ErrorContains(t, err, "status 500")
+	assert.Zero(t, fake.rejected)
+
+	// Secret yang salah ditolak oleh server.
+	wrong := fake.config(srv.URL)
+	wrong.SecretAccessKey = "salah"
+	bad, err := storage.NewS3(wrong, services.CoverURLPrefix)
+	require.NoError(t, err)
+	err = bad.Put(ctx, "c.jpg", strings.NewReader("x"), "image/jpeg")
+	assert.ErrorContains(t, err, "status 403")
+	assert.Equal(t, 1, fake.rejected)
+
+	public := fake.config(srv.URL)
+	public.PublicURL = "https://cdn.contoh.id/covers/"
+	withCDN, err := storage.NewS3(public, services.CoverURLPrefix)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.contoh.id/covers/a.jpg", withCDN.URL("a.jpg"))
+	assert.Equal(t, "a.jpg", storage.KeyFromURL(withCDN.URL("a.jpg")))
+
+	for name, cfg := range map[string]storage.S3Config{
+		"endpoint kosong":   {Region: "r", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"},
+		"endpoint relatif":  {Endpoint: "localhost:9000", Region: "r", Bucket: "b", AccessKeyID: "a", SecretAccessKey: "s"},
+		"tanpa bucket":      {Endpoint: srv.URL, Region: "r", AccessKeyID: "a", SecretAccessKey: "s"},
+		"tanpa credentials": {Endpoint: srv.URL, Region: "r", Bucket: "b"},
+	} {
+		_, err := storage.NewS3(cfg, "")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCoversStoredInS3(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	s3, err := storage.NewS3(fake.config(srv.URL), services.CoverURLPrefix)
+	require.NoError(t, err)
+	router, _ := newTestRouter(t, services.WithStorage(s3))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/api/v1/tasks", encodeJPEG(t, 1000, 500)))
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	require.Len(t, fake.keys(), 3)
+	for _, path := range created.Data.CoverPaths() {
+		assert.Contains(t, fake.keys(), storage.KeyFromURL(path))
+	}
+
+	// Cover disajikan aplikasi dari bucket dan tetap memerlukan login.
+	rec = doJSON(t, router, http.MethodGet, created.Data.Cover, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/jpeg", rec.Header().Get("Content-Type"))
+	assert.NotEmpty(t, rec.Header().Get("Last-Modified"))
+	assert.Equal(t, fake.objects[storage.KeyFromURL(created.Data.Cover)].data, rec.Body.Bytes())
+
+	rec = doJSON(t, router, http.MethodGet, "/static/uploads/tasks/tidak-ada.jpg", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = doJSON(t, router, http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d/cover", created.Data.ID), nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, fake.keys())
+	assert.Zero(t, fake.rejected)
+}
+
+func TestReplacingCoverInS3KeepsOldObjectsUntilCommit(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	s3, err := storage.NewS3(fake.config(srv.URL), services.CoverURLPrefix)
+	require.NoError(t, err)
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), t.TempDir(),
+		services.WithStorage(s3))
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"},
+		createMultipartFileHeader(t, "cover", "a.jpg", encodeJPEG(t, 40, 20)))
+	require.NoError(t, err)
+	old := fake.keys()
+
+	// Put varian kedua gagal: varian yang sudah masuk bucket dihapus lagi
+	// dan cover lama tidak disentuh.
+	fake.failIn(2)
+	_, err = service.UpdateTask(ctx, task.ID, &models.Task{},
+		createMultipartFileHeader(t, "cover", "b.jpg", encodeJPEG(t, 60, 30)))
+	require.Error(t, err)
+	assert.Equal(t, old, fake.keys())
+
+	updated, err := service.UpdateTask(ctx, task.ID, &models.Task{},
+		createMultipartFileHeader(t, "cover", "b.jpg", encodeJPEG(t, 60, 30)))
+	require.NoError(t, err)
+	keys := fake.keys()
+	require.Len(t, keys, 3)
+	for _, path := range updated.CoverPaths() {
+		assert.Contains(t, keys, storage.KeyFromURL(path))
+	}
+}
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/nabilulilalbab/welcomesite/migrations"
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+func TestParseTagNames(t *testing.T) {
+	assert.Equal(t, []string{"react js", "frontend"}, models.ParseTagNames("  React   JS , frontend,,react js, "))
+	assert.Empty(t, models.ParseTagNames(""))
+}
+
+func TestTagNameLengthCountsCharacters(t *testing.T) {
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), t.TempDir())
+
+	// 50 karakter multi-byte masih di bawah batas meski lebih dari 50 byte.
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website",
+		Tags: tagList(strings.Repeat("é", models.MaxTagNameLength))}, nil)
+	require.NoError(t, err)
+	assert.Len(t, task.Tags, 1)
+
+	_, err = service.CreateTask(ctx, &models.Task{Judul: "B", Tipe: "Website",
+		Tags: tagList(strings.Repeat("日", models.MaxTagNameLength+1))}, nil)
+	require.ErrorIs(t, err, services.ErrInvalidTag)
+	assert.True(t, utf8.ValidString(err.Error()), "pesan error: %q", err.Error())
+	assert.Contains(t, err.Error(), strings.Repeat("日", 20)+"...")
+}
+
+func TestMigrateLegacyTags(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	require.NoError(t, err)
+	// Skema lama: tags masih berupa kolom teks.
+	require.NoError(t, db.Exec(`CREATE TABLE tasks (
+		id integer PRIMARY KEY AUTOINCREMENT, judul varchar(255) NOT NULL,
+		status varchar(50) NOT NULL DEFAULT 'todo', tipe varchar(50) NOT NULL,
+		path_project text, link_website text, tags text, catatan text,
+		cover varchar(255), created_at datetime, updated_at datetime)`).Error)
+	require.NoError(t, db.Exec(`INSERT INTO tasks (judul, tipe, tags) VALUES
+		('A', 'Website', 'Go, backend'), ('B', 'Website', 'go,  BACKEND ,api'), ('C', 'Website', NULL)`).Error)
+
+	_, err = migrations.New(db).Up()
+	require.NoError(t, err)
+	assert.False(t, db.Migrator().HasColumn("tasks", "tags"))
+	// Dipanggil ulang tidak melakukan apa-apa.
+	applied, err := migrations.New(db).Up()
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	tags, err := repositories.NewTagRepository(db).FindAllWithCount()
+	require.NoError(t, err)
+	counts := map[string]int64{}
+	for _, tag := range tags {
+		counts[tag.Name] = tag.TaskCount
+	}
+	assert.Equal(t, map[string]int64{"go": 2, "backend": 2, "api": 1}, counts)
+}
+
+func TestTagService(t *testing.T) {
+	db := newIsolatedDB(t)
+	taskService := services.NewTaskService(repositories.NewTaskRepository(db), t.TempDir())
+	tagService := services.NewTagService(repositories.NewTagRepository(db))
+
+	a, err := taskService.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website", Tags: tagList("golang, api")}, nil)
+	require.NoError(t, err)
+	_, err = taskService.CreateTask(ctx, &models.Task{Judul: "B", Tipe: "Website", Tags: tagList("go")}, nil)
+	require.NoError(t, err)
+
+	tags, err := tagService.GetAllTags(ctx)
+	require.NoError(t, err)
+	byName := map[string]models.Tag{}
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+
+	t.Run("rename to existing name conflicts", func(t *testing.T) {
+		_, err := tagService.RenameTag(ctx, byName["golang"].ID, " GO ")
+		assert.ErrorIs(t, err, services.ErrTagExists)
+	})
+
+	t.Run("merge moves tasks", func(t *testing.T) {
+		target, err := tagService.MergeTags(ctx, byName["golang"].ID, byName["go"].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "go", target.Name)
+
+		page, err := taskService.ListTasks(ctx, models.TaskQuery{Tags: []string{"go"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A", "B"}, judulOf(page.Tasks))
+
+		_, err = tagService.RenameTag(ctx, byName["golang"].ID, "x")
+		assert.ErrorIs(t, err, services.ErrTagNotFound)
+	})
+
+	t.Run("rename changes every task", func(t *testing.T) {
+		_, err := tagService.RenameTag(ctx, byName["api"].ID, "REST API")
+		require.NoError(t, err)
+		task, err := taskService.GetTaskByID(ctx, a.ID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"go", "rest api"}, models.TagNames(task.Tags))
+	})
+
+	t.Run("delete detaches tag", func(t *testing.T) {
+		require.NoError(t, tagService.DeleteTag(ctx, byName["api"].ID))
+		task, err := taskService.GetTaskByID(ctx, a.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go"}, models.TagNames(task.Tags))
+	})
+}
+
+// Perubahan tag tercatat di riwayat setiap task yang memakainya, termasuk
+// task di trash.
+func TestTagChangesRecordTaskEvents(t *testing.T) {
+	db := newIsolatedDB(t)
+	taskService := services.NewTaskService(repositories.NewTaskRepository(db), t.TempDir())
+	tagService := services.NewTagService(repositories.NewTagRepository(db))
+	actorCtx := services.WithActor(ctx, "budi")
+
+	a, err := taskService.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website", Tags: tagList("golang, api")}, nil)
+	require.NoError(t, err)
+	b, err := taskService.CreateTask(ctx, &models.Task{Judul: "B", Tipe: "Website", Tags: tagList("go")}, nil)
+	require.NoError(t, err)
+	c, err := taskService.CreateTask(ctx, &models.Task{Judul: "C", Tipe: "Website", Tags: tagList("api")}, nil)
+	require.NoError(t, err)
+	require.NoError(t, taskService.DeleteTask(ctx, c.ID))
+
+	tags, err := tagService.GetAllTags(ctx)
+	require.NoError(t, err)
+	byName := map[string]models.Tag{}
+	for _, tag := range tags {
+		byName[tag.Name] = tag
+	}
+	lastTagChange := func(taskID uint) models.FieldChanges {
+		t.Helper()
+		events, err := taskService.GetTaskEvents(ctx, taskID)
+		require.NoError(t, err)
+		require.NotEmpty(t, events)
+		assert.Equal(t, models.TaskEventUpdate, events[0].Operation)
+		assert.Equal(t, "budi", events[0].Actor)
+		return events[0].Changes
+	}
+	eventCount := func(taskID uint) int {
+		t.Helper()
+		events, err := taskService.GetTaskEvents(ctx, taskID)
+		require.NoError(t, err)
+		return len(events)
+	}
+
+	_, err = tagService.RenameTag(actorCtx, byName["api"].ID, "rest")
+	require.NoError(t, err)
+	assert.Equal(t, models.FieldChanges{{Field: "tags", Old: "api, golang", New: "golang, rest"}}, lastTagChange(a.ID))
+	assert.Equal(t, models.FieldChanges{{Field: "tags", Old: "api", New: "rest"}}, lastTagChange(c.ID))
+
+	before := eventCount(b.ID)
+	_, err = tagService.MergeTags(actorCtx, byName["golang"].ID, byName["go"].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.FieldChanges{{Field: "tags", Old: "golang, rest", New: "go, rest"}}, lastTagChange(a.ID))
+	assert.Equal(t, before, eventCount(b.ID), "tag task B tidak berubah")
+
+	require.NoError(t, tagService.DeleteTag(actorCtx, byName["go"].ID))
+	assert.Equal(t, models.FieldChanges{{Field: "tags", Old: "go, rest", New: "rest"}}, lastTagChange(a.ID))
+	assert.Equal(t, models.FieldChanges{{Field: "tags", Old: "go", New: ""}}, lastTagChange(b.ID))
+}
+
+func TestTagAPI(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	rec := doJSON(t, router, http.MethodPost, "/api/v1/tasks", map[string]any{
+		"judul": "Belajar", "tipe": "Website", "tags": []string{"Go", " go ", "API"},
+	})
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, []string{"go", "api"}, models.TagNames(created.Data.Tags))
+
+	rec = doJSON(t, router, http.MethodPost, "/api/v1/tags", map[string]any{"name": "Go"})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = doJSON(t, router, http.MethodPost, "/api/v1/tags", map[string]any{"name": "  "})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = doJSON(t, router, http.MethodGet, "/api/v1/tags", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Data []models.Tag `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 2)
+	assert.Equal(t, "api", list.Data[0].Name)
+	assert.Equal(t, int64(1), list.Data[0].TaskCount)
+
+	rec = doJSON(t, router, http.MethodPost, fmt.Sprintf("/api/v1/tags/%d/merge", list.Data[0].ID), map[string]any{"into": list.Data[1].ID})
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doJSON(t, router, http.MethodDelete, fmt.Sprintf("/api/v1/tags/%d", list.Data[0].ID), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/nabilulilalbab/welcomesite"
+	"github.com/nabilulilalbab/welcomesite/controllers"
+	"github.com/nabilulilalbab/welcomesite/migrations"
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/routes"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/view"
+)
+
+// ctx dipakai test yang memanggil service secara langsung.
+var ctx = context.Background()
+
+// newIsolatedDB membuat database in-memory yang tidak dibagi dengan test lain.
+func newIsolatedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+	_, err = migrations.New(db).Up()
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func newTestRouter(t *testing.T, opts ...services.TaskServiceOption) (http.Handler, repositories.TaskRepository) {
+	t.Helper()
+	router, auth, repo := newAuthRouter(t, opts...)
+	// Semua request test dikirim sebagai pengguna yang sudah login; task
+	// yang dibuat lewat repository yang dikembalikan ikut menjadi miliknya.
+	user, token := loginTestUser(t, auth, "tester")
+	return withSession(router, auth, token), repo.WithOwner(user.ID)
+}
+
+// newAuthRouter membuat router lengkap yang mewajibkan login, tanpa
+// pengguna apa pun.
+func newAuthRouter(t *testing.T, opts ...services.TaskServiceOption) (http.Handler, services.AuthService, repositories.TaskRepository) {
+	t.Helper()
+	db := newIsolatedDB(t)
+	repo := repositories.NewTaskRepository(db)
+	service := services.NewTaskService(repo, t.TempDir(), opts...)
+	taskCtrl := controllers.NewTaskController(service, view.ParseTemplates())
+	apiCtrl := controllers.NewTaskAPIController(service)
+	tagCtrl := controllers.NewTagAPIController(services.NewTagService(repositories.NewTagRepository(db)))
+	auth := services.NewAuthService(repositories.NewUserRepository(db))
+	authCtrl := controllers.NewAuthController(auth, view.ParseTemplates(), false)
+	return routes.NewRouter(taskCtrl, apiCtrl, tagCtrl, authCtrl, welcomesite.StaticFS), auth, repo
+}
+
+// loginTestUser mendaftarkan pengguna lalu login dan mengembalikan token sesinya.
+func loginTestUser(t *testing.T, auth services.AuthService, username string) (*models.User, string) {
+	t.Helper()
+	user, err := auth.Register(ctx, username, "rahasia123")
+	require.NoError(t, err)
+	token, _, err := auth.Login(ctx, username, "rahasia123")
+	require.NoError(t, err)
+	return user, token
+}
+
+// withSession menambahkan cookie sesi beserta header token CSRF-nya ke
+// setiap request yang belum membawanya.
+func withSession(h http.Handler, auth services.AuthService, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie(controllers.SessionCookieName); err != nil {
+			r.AddCookie(&http.Cookie{Name: controllers.SessionCookieName, Value: token})
+			if r.Header.Get(controllers.CSRFHeaderName) == "" {
+				r.Header.Set(controllers.CSRFHeaderName, auth.CSRFToken(token))
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func doJSON(t *testing.T, h http.Handler, method, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+type apiTaskResponse struct {
+	Data models.Task `json:"data"`
+}
+
+type apiErrorBody struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func TestAPICreateAndGetTask(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	rec := doJSON(t, router, http.MethodPost, "/api/v1/tasks", map[string]any{
+		"judul": "Belajar Go",
+		"tipe":  "Website",
+	})
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	var created apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.Equal(t, "Belajar Go", created.Data.Judul)
+	assert.Equal(t, "todo", created.Data.Status)
+	assert.Equal(t, fmt.Sprintf("/api/v1/tasks/%d", created.Data.ID), rec.Header().Get("Location"))
+
+	rec = doJSON(t, router, http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d", created.Data.ID), nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var fetched apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &fetched))
+	assert.Equal(t, created.Data.ID, fetched.Data.ID)
+}
+
+func TestAPIListUpdateDeleteTask(t *testing.T) {
+	router, repo := newTestRouter(t)
+	task, err := repo.Create(&models.Task{Judul: "Lama", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+
+	rec := doJSON(t, router, http.MethodGet, "/api/v1/tasks", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Data []models.Task `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	assert.Len(t, list.Data, 1)
+
+	rec = doJSON(t, router, http.MethodPut, path, map[string]any{"judul": "Baru", "tipe": "Website", "status": "done"})
+	require.Equal(t, http.StatusOK, rec.Code)
+	var updated apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+	assert.Equal(t, "Baru", updated.Data.Judul)
+	assert.Equal(t, "done", updated.Data.Status)
+
+	rec = doJSON(t, router, http.MethodDelete, path, nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = doJSON(t, router, http.MethodGet, path, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAPIErrors(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       any
+		wantStatus int
+		wantCode   string
+	}{
+		{"invalid id", http.MethodGet, "/api/v1/tasks/abc", nil, http.StatusBadRequest, "invalid_id"},
+		{"not found", http.MethodGet, "/api/v1/tasks/999", nil, http.StatusNotFound, "not_found"},
+		{"update not found", http.MethodPut, "/api/v1/tasks/999", map[string]any{"judul": "x", "tipe": "Website"}, http.StatusNotFound, "not_found"},
+		{"missing judul", http.MethodPost, "/api/v1/tasks", map[string]any{"tipe": "Website"}, http.StatusUnprocessableEntity, "validation_failed"},
+		{"unknown field", http.MethodPost, "/api/v1/tasks", map[string]any{"title": "x"}, http.StatusBadRequest, "invalid_json"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := doJSON(t, router, tc.method, tc.path, tc.body)
+			require.Equal(t, tc.wantStatus, rec.Code)
+			var body apiErrorBody
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tc.wantCode, body.Error.Code)
+			assert.NotEmpty(t, body.Error.Message)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+func TestDiffTask(t *testing.T) {
+	path := "/home/x"
+	before := &models.Task{Judul: "A", Status: "todo", Tipe: "Website", Tags: tagList("go")}
+	after := &models.Task{Judul: "B", Status: "todo", Tipe: "Website", PathProject: &path, Tags: tagList("go, api")}
+
+	assert.Equal(t, models.FieldChanges{
+		{Field: "judul", Old: "A", New: "B"},
+		{Field: "path_project", Old: "", New: "/home/x"},
+		{Field: "tags", Old: "go", New: "go, api"},
+	}, models.DiffTask(before, after))
+	assert.Empty(t, models.DiffTask(before, before))
+}
+
+func TestTaskEventsRecorded(t *testing.T) {
+	db := newIsolatedDB(t)
+	repo := repositories.NewTaskRepository(db)
+	service := services.NewTaskService(repo, t.TempDir())
+	actorCtx := services.WithActor(ctx, "10.0.0.7")
+
+	task, err := service.CreateTask(actorCtx, &models.Task{Judul: "Lama", Tipe: "Website"}, nil)
+	require.NoError(t, err)
+
+	// Update tanpa perubahan tidak menghasilkan event.
+	_, err = service.UpdateTask(actorCtx, task.ID, &models.Task{Judul: "Lama"}, nil)
+	require.NoError(t, err)
+
+	_, err = service.UpdateTask(actorCtx, task.ID, &models.Task{Judul: "Baru", Status: "inprogress"}, nil)
+	require.NoError(t, err)
+
+	cover := createMultipartFileHeader(t, "cover", "cover.jpg", dummyJPEG)
+	_, err = service.UpdateTask(ctx, task.ID, &models.Task{Catatan: "catatan"}, cover)
+	require.NoError(t, err)
+
+	events, err := service.GetTaskEvents(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	// Terbaru lebih dulu.
+	assert.Equal(t, models.TaskEventCover, events[0].Operation)
+	assert.Equal(t, services.SystemActor, events[0].Actor)
+	require.Len(t, events[0].Changes, 1)
+	assert.Equal(t, "cover", events[0].Changes[0].Field)
+	assert.Empty(t, events[0].Changes[0].Old)
+
+	assert.Equal(t, models.TaskEventUpdate, events[1].Operation)
+	assert.Equal(t, models.FieldChanges{{Field: "catatan", Old: "", New: "catatan"}}, events[1].Changes)
+
+	assert.Equal(t, models.TaskEventUpdate, events[2].Operation)
+	assert.Equal(t, "10.0.0.7", events[2].Actor)
+	assert.Equal(t, models.FieldChanges{
+		{Field: "judul", Old: "Lama", New: "Baru"},
+		{Field: "status", Old: "todo", New: "inprogress"},
+	}, events[2].Changes)
+
+	assert.Equal(t, models.TaskEventCreate, events[3].Operation)
+
+	require.NoError(t, service.DeleteTask(actorCtx, task.ID))
+	all, err := repo.FindEvents(task.ID)
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+	assert.Equal(t, models.TaskEventDelete, all[0].Operation)
+	assert.Equal(t, "10.0.0.7", all[0].Actor)
+}
+
+func TestTaskEventRollsBackWithUpdate(t *testing.T) {
+	repo := repositories.NewTaskRepository(newIsolatedDB(t))
+	service := services.NewTaskService(repo, t.TempDir(), services.WithWorkflow(reviewWorkflow()))
+
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"}, nil)
+	require.NoError(t, err)
+	_, err = service.UpdateTask(ctx, task.ID, &models.Task{Judul: "B", Status: "done"}, nil)
+	require.ErrorIs(t, err, services.ErrIllegalTransition)
+
+	events, err := service.GetTaskEvents(ctx, task.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, models.TaskEventCreate, events[0].Operation)
+}
+
+func TestAPIListTaskEvents(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	rec := doJSON(t, router, http.MethodPost, "/api/v1/tasks", map[string]any{"judul": "A", "tipe": "Website"})
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	path := fmt.Sprintf("/api/v1/tasks/%d", created.Data.ID)
+
+	rec = doJSON(t, router, http.MethodPut, path, map[string]any{"judul": "B", "tipe": "Website"})
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doJSON(t, router, http.MethodGet, path+"/events", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data []models.TaskEvent `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data, 2)
+	assert.Equal(t, models.TaskEventUpdate, body.Data[0].Operation)
+	// Pelaku perubahan adalah pengguna yang login.
+	assert.Equal(t, "tester", body.Data[0].Actor)
+	assert.Equal(t, models.FieldChanges{{Field: "judul", Old: "A", New: "B"}}, body.Data[0].Changes)
+
+	rec = doJSON(t, router, http.MethodGet, "/api/v1/tasks/999/events", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
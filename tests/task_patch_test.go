@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+// newPatchFixture membuat task dengan semua field yang bisa dikosongkan
+// sudah terisi.
+func newPatchFixture(t *testing.T) (services.TaskService, *models.Task) {
+	t.Helper()
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), t.TempDir())
+	task, err := service.CreateTask(ctx, &models.Task{
+		Judul:       "Awal",
+		Tipe:        "Project Local",
+		PathProject: strPtr("/srv/awal"),
+		LinkWebsite: strPtr("https://awal.id"),
+		Tags:        models.TagsFromNames([]string{"go", "web"}),
+		Catatan:     "catatan awal",
+		Launcher:    "xterm",
+	}, nil)
+	require.NoError(t, err)
+	return service, task
+}
+
+func TestPatchTaskFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch models.TaskPatch
+		check func(t *testing.T, task *models.Task)
+	}{
+		{"kosong tidak mengubah apa pun", models.TaskPatch{}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "Awal", task.Judul)
+			assert.Equal(t, "/srv/awal", *task.PathProject)
+			assert.Equal(t, "https://awal.id", *task.LinkWebsite)
+			assert.Equal(t, []string{"go", "web"}, models.TagNames(task.Tags))
+			assert.Equal(t, "catatan awal", task.Catatan)
+			assert.Equal(t, "xterm", task.Launcher)
+		}},
+		{"set judul", models.TaskPatch{Judul: models.SetField("Baru")}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "Baru", task.Judul)
+			assert.Equal(t, "catatan awal", task.Catatan)
+		}},
+		{"set tipe", models.TaskPatch{Tipe: models.SetField("Website")}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "Website", task.Tipe)
+		}},
+		{"set status", models.TaskPatch{Status: models.SetField("done")}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "done", task.Status)
+		}},
+		{"set path", models.TaskPatch{PathProject: models.SetField("/srv/baru")}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "/srv/baru", *task.PathProject)
+		}},
+		{"clear path", models.TaskPatch{PathProject: models.SetField("")}, func(t *testing.T, task *models.Task) {
+			assert.Nil(t, task.PathProject)
+			assert.NotNil(t, task.LinkWebsite)
+		}},
+		{"set link", models.TaskPatch{LinkWebsite: models.SetField("https://baru.id")}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "https://baru.id", *task.LinkWebsite)
+		}},
+		{"clear link", models.TaskPatch{LinkWebsite: models.SetField("")}, func(t *testing.T, task *models.Task) {
+			assert.Nil(t, task.LinkWebsite)
+			assert.NotNil(t, task.PathProject)
+		}},
+		{"set tags", models.TaskPatch{Tags: models.SetField([]string{"Rust"})}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, []string{"rust"}, models.TagNames(task.Tags))
+		}},
+		{"clear tags", models.TaskPatch{Tags: models.SetField([]string{})}, func(t *testing.T, task *models.Task) {
+			assert.Empty(t, task.Tags)
+		}},
+		{"clear tags nil", models.TaskPatch{Tags: models.PatchField[[]string]{Set: true}}, func(t *testing.T, task *models.Task) {
+			assert.Empty(t, task.Tags)
+		}},
+		{"set catatan", models.TaskPatch{Catatan: models.SetField("lain")}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "lain", task.Catatan)
+		}},
+		{"clear catatan", models.TaskPatch{Catatan: models.SetField("")}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "", task.Catatan)
+			assert.Equal(t, "Awal", task.Judul)
+		}},
+		{"set launcher", models.TaskPatch{Launcher: models.SetField("kitty")}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "kitty", task.Launcher)
+		}},
+		{"clear launcher", models.TaskPatch{Launcher: models.SetField("")}, func(t *testing.T, task *models.Task) {
+			assert.Equal(t, "", task.Launcher)
+		}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service, task := newPatchFixture(t)
+			updated, err := service.PatchTask(ctx, task.ID, &tc.patch, nil)
+			require.NoError(t, err)
+			tc.check(t, updated)
+
+			// Hasil yang tersimpan sama dengan yang dikembalikan.
+			stored, err := service.GetTaskByID(ctx, task.ID)
+			require.NoError(t, err)
+			tc.check(t, stored)
+		})
+	}
+}
+
+func TestPatchTaskRecordsClearedFields(t *testing.T) {
+	service, task := newPatchFixture(t)
+	_, err := service.PatchTask(ctx, task.ID, &models.TaskPatch{
+		Catatan: models.SetField(""),
+		Tags:    models.SetField([]string{}),
+	}, nil)
+	require.NoError(t, err)
+
+	events, err := service.GetTaskEvents(ctx, task.ID)
+	require.NoError(t, err)
+	last := events[0]
+	assert.Equal(t, models.TaskEventUpdate, last.Operation)
+	assert.ElementsMatch(t, models.FieldChanges{
+		{Field: "tags", Old: "go, web", New: ""},
+		{Field: "catatan", Old: "catatan awal", New: ""},
+	}, last.Changes)
+}
+
+func TestPatchTaskRejectsEmptyStatusAndUnknownLauncher(t *testing.T) {
+	service, task := newPatchFixture(t)
+	_, err := service.PatchTask(ctx, task.ID, &models.TaskPatch{Status: models.SetField("")}, nil)
+	assert.ErrorIs(t, err, services.ErrUnknownStatus)
+	_, err = service.PatchTask(ctx, task.ID, &models.TaskPatch{Launcher: models.SetField("tidak-ada")}, nil)
+	assert.ErrorIs(t, err, services.ErrUnknownLauncher)
+	_, err = service.PatchTask(ctx, 999, &models.TaskPatch{}, nil)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+}
+
+func TestTaskPatchJSON(t *testing.T) {
+	var patch models.TaskPatch
+	require.NoError(t, json.Unmarshal([]byte(`{"judul":"A","catatan":null,"tags":[]}`), &patch))
+	assert.Equal(t, models.SetField("A"), patch.Judul)
+	assert.Equal(t, models.PatchField[string]{Set: true}, patch.Catatan)
+	assert.True(t, patch.Tags.Set)
+	assert.Empty(t, patch.Tags.Value)
+	assert.False(t, patch.PathProject.Set)
+	assert.False(t, patch.Status.Set)
+}
+
+func TestAPIPatchTask(t *testing.T) {
+	router, repo := newTestRouter(t)
+	task, err := repo.Create(&models.Task{
+		Judul:       "Awal",
+		Tipe:        "Website",
+		Status:      "todo",
+		LinkWebsite: strPtr("https://awal.id"),
+		Catatan:     "catatan",
+	})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+
+	rec := doJSON(t, router, http.MethodPatch, path, map[string]any{"link_website": nil, "catatan": ""})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var resp apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Nil(t, resp.Data.LinkWebsite)
+	assert.Equal(t, "", resp.Data.Catatan)
+	assert.Equal(t, "Awal", resp.Data.Judul)
+
+	rec = doJSON(t, router, http.MethodPatch, path, map[string]any{"judul": ""})
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	var body apiValidationBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, []string{"judul"}, sortedKeys(body.Error.Fields))
+
+	rec = doJSON(t, router, http.MethodPatch, path, map[string]any{"title": "x"})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = doJSON(t, router, http.MethodPatch, "/api/v1/tasks/999", map[string]any{"judul": "x"})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+}
+
+// PUT mengganti seluruh task: field opsional yang tidak dikirim atau kosong
+// dikosongkan, berbeda dengan PATCH yang membiarkannya.
+func TestAPIPutReplacesTask(t *testing.T) {
+	router, repo := newTestRouter(t)
+	task, err := repo.Create(&models.Task{
+		Judul:       "Awal",
+		Tipe:        "Project Local",
+		Status:      "inprogress",
+		PathProject: strPtr("/srv/awal"),
+		LinkWebsite: strPtr("https://awal.id"),
+		Catatan:     "catatan",
+		Tags:        tagList("go"),
+	})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+
+	rec := doJSON(t, router, http.MethodPatch, path, map[string]any{"judul": "Patch"})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	stored, err := repo.FindByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "catatan", stored.Catatan, "PATCH membiarkan field yang tidak dikirim")
+	assert.Equal(t, []string{"go"}, models.TagNames(stored.Tags))
+
+	rec = doJSON(t, router, http.MethodPut, path, map[string]any{"judul": "Lagi", "tipe": "Website", "link_website": ""})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	stored, err = repo.FindByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Lagi", stored.Judul)
+	assert.Equal(t, "Website", stored.Tipe)
+	assert.Equal(t, "inprogress", stored.Status, "status yang tidak dikirim tidak diubah")
+	assert.Nil(t, stored.PathProject)
+	assert.Nil(t, stored.LinkWebsite)
+	assert.Empty(t, stored.Catatan)
+	assert.Empty(t, stored.Tags)
+
+	rec = doJSON(t, router, http.MethodPut, path, map[string]any{"judul": "Tanpa tipe"})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestHTMLUpdateClearsFields(t *testing.T) {
+	router, repo := newTestRouter(t)
+	task, err := repo.Create(&models.Task{
+		Judul:       "Awal",
+		Tipe:        "Project Local",
+		Status:      "todo",
+		PathProject: strPtr("/srv/awal"),
+		Catatan:     "catatan",
+	})
+	require.NoError(t, err)
+
+	// Modal edit mengirim semua input; yang kosong dikosongkan.
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartFields(t, fmt.Sprintf("/task/update/%d", task.ID), map[string]string{
+		"judul":        "Awal",
+		"tipe":         "Website",
+		"status":       "todo",
+		"path_project": "",
+		"link_website": "https://baru.id",
+		"tags":         "",
+		"catatan":      "",
+		"launcher":     "",
+	}))
+	require.Equal(t, http.StatusSeeOther, rec.Code, rec.Body.String())
+
+	stored, err := repo.FindByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Website", stored.Tipe)
+	assert.Nil(t, stored.PathProject)
+	assert.Equal(t, "https://baru.id", *stored.LinkWebsite)
+	assert.Equal(t, "", stored.Catatan)
+	assert.Empty(t, stored.Tags)
+}
@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+func seedQueryTasks(t *testing.T, repo repositories.TaskRepository) {
+	t.Helper()
+	seed := []models.Task{
+		{Judul: "Belajar Go", Status: "todo", Tipe: "Project Local", Tags: tagList("go, backend"), Catatan: "channel dan goroutine"},
+		{Judul: "Landing page", Status: "done", Tipe: "Website", Tags: tagList("frontend,css")},
+		{Judul: "REST API", Status: "inprogress", Tipe: "Project Local", Tags: tagList("Go,api"), Catatan: "pakai httprouter"},
+		{Judul: "Blog pribadi", Status: "todo", Tipe: "Website", Tags: tagList("gohugo")},
+	}
+	service := services.NewTaskService(repo, t.TempDir())
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.Local)
+	for i := range seed {
+		seed[i].CreatedAt = base.AddDate(0, 0, i)
+		seed[i].UpdatedAt = base.AddDate(0, 0, i)
+		_, err := service.CreateTask(ctx, &seed[i], nil)
+		require.NoError(t, err)
+	}
+}
+
+func tagList(input string) []models.Tag {
+	return models.TagsFromNames(models.ParseTagNames(input))
+}
+
+func judulOf(tasks []models.Task) []string {
+	var out []string
+	for _, task := range tasks {
+		out = append(out, task.Judul)
+	}
+	return out
+}
+
+func TestListTasksFilters(t *testing.T) {
+	repo := repositories.NewTaskRepository(newIsolatedDB(t))
+	seedQueryTasks(t, repo)
+	service := services.NewTaskService(repo, t.TempDir())
+
+	day := func(d int) *time.Time {
+		v := time.Date(2024, 1, d, 0, 0, 0, 0, time.Local)
+		return &v
+	}
+
+	tests := []struct {
+		name  string
+		query models.TaskQuery
+		want  []string
+	}{
+		{"no filter", models.TaskQuery{}, []string{"Belajar Go", "Landing page", "REST API", "Blog pribadi"}},
+		{"status", models.TaskQuery{Status: "todo"}, []string{"Belajar Go", "Blog pribadi"}},
+		{"tipe", models.TaskQuery{Tipe: "Website"}, []string{"Landing page", "Blog pribadi"}},
+		{"tag is exact and case-insensitive", models.TaskQuery{Tags: []string{"GO"}}, []string{"Belajar Go", "REST API"}},
+		{"all tags must match", models.TaskQuery{Tags: []string{"go", "api"}}, []string{"REST API"}},
+		{"search judul and catatan", models.TaskQuery{Search: "GOROUTINE"}, []string{"Belajar Go"}},
+		{"created range", models.TaskQuery{CreatedFrom: day(2), CreatedTo: day(3)}, []string{"Landing page"}},
+		{"updated from", models.TaskQuery{UpdatedFrom: day(3)}, []string{"REST API", "Blog pribadi"}},
+		{"sort judul desc", models.TaskQuery{SortBy: "judul", SortDir: "desc"}, []string{"REST API", "Landing page", "Blog pribadi", "Belajar Go"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := service.ListTasks(ctx, tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, judulOf(page.Tasks))
+			assert.Equal(t, int64(len(tc.want)), page.Total)
+		})
+	}
+}
+
+func TestListTasksSearchIsLiteral(t *testing.T) {
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), t.TempDir())
+	for _, judul := range []string{"Diskon 50%", "Diskon 50 persen", "snake_case", "snakecase", `C:\tmp`, "C:tmp"} {
+		_, err := service.CreateTask(ctx, &models.Task{Judul: judul, Tipe: "Website"}, nil)
+		require.NoError(t, err)
+	}
+
+	for search, want := range map[string][]string{
+		"%":      {"Diskon 50%"},
+		"50%":    {"Diskon 50%"},
+		"e_c":    {"snake_case"},
+		`:\`:     {`C:\tmp`},
+		"diskon": {"Diskon 50%", "Diskon 50 persen"},
+	} {
+		page, err := service.ListTasks(ctx, models.TaskQuery{Search: search})
+		require.NoError(t, err)
+		assert.Equal(t, want, judulOf(page.Tasks), "cari %q", search)
+	}
+}
+
+func TestListTasksPagination(t *testing.T) {
+	repo := repositories.NewTaskRepository(newIsolatedDB(t))
+	seedQueryTasks(t, repo)
+	service := services.NewTaskService(repo, t.TempDir())
+
+	first, err := service.ListTasks(ctx, models.TaskQuery{Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Belajar Go", "Landing page", "REST API"}, judulOf(first.Tasks))
+	require.NotEmpty(t, first.NextCursor)
+
+	second, err := service.ListTasks(ctx, models.TaskQuery{Limit: 3, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Blog pribadi"}, judulOf(second.Tasks))
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestListTasksInvalidQuery(t *testing.T) {
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), t.TempDir())
+
+	for _, query := range []models.TaskQuery{
+		{SortBy: "password"},
+		{SortDir: "sideways"},
+		{Limit: -1},
+		{Cursor: "bukan-cursor"},
+	} {
+		_, err := service.ListTasks(ctx, query)
+		assert.ErrorIs(t, err, services.ErrInvalidQuery)
+	}
+}
+
+func TestListTaskPageReadsQueryString(t *testing.T) {
+	router, repo := newTestRouter(t)
+	seedQueryTasks(t, repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/?status=done", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Landing page")
+	assert.NotContains(t, rec.Body.String(), "Belajar Go")
+
+	req = httptest.NewRequest(http.MethodGet, "/?created_from=kemarin", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
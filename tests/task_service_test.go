@@ -3,38 +3,36 @@ package tests
 import (
 	"bytes"
 	"errors"
+	"image"
+	"image/jpeg"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/nabilulilalbab/welcomesite/migrations"
 	"github.com/nabilulilalbab/welcomesite/models"
 	"github.com/nabilulilalbab/welcomesite/repositories"
 	"github.com/nabilulilalbab/welcomesite/services"
 	mockRepo "github.com/nabilulilalbab/welcomesite/tests/mock"
 )
 
-var dummyJPEG = []byte{
-	// Minimal valid JPEG file (1x1 px)
-	0xFF, 0xD8, 0xFF, 0xE0, 0x00, 0x10,
-	0x4A, 0x46, 0x49, 0x46, 0x00, 0x01,
-	0x01, 0x00, 0x00, 0x01, 0x00, 0x01,
-	0x00, 0x00, 0xFF, 0xDB, 0x00, 0x43,
-	0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
-	0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
-	0xFF, 0xC0, 0x00, 0x0B, 0x08, 0x00,
-	0x01, 0x00, 0x01, 0x01, 0x01, 0x11,
-	0x00, 0xFF, 0xC4, 0x00, 0x14, 0x00,
-	0x01, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
-	0x00, 0x00, 0x00, 0xFF, 0xDA, 0x00,
-	0x08, 0x01, 0x01, 0x00, 0x00, 0x3F,
-	0x00, 0xD2, 0xCF, 0x20, 0xFF, 0xD9,
+// dummyJPEG berisi JPEG valid 1x1 px yang di-encode saat test dimulai.
+var dummyJPEG = encodeDummyJPEG()
+
+func encodeDummyJPEG() []byte {
+	buf := new(bytes.Buffer)
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	if err := jpeg.Encode(buf, img, nil); err != nil {
+		panic("failed to encode dummy JPEG")
+	}
+	return buf.Bytes()
 }
 
 func setupTestDB() *gorm.DB {
@@ -42,7 +40,7 @@ func setupTestDB() *gorm.DB {
 	if err != nil {
 		panic("failed to connect to test database")
 	}
-	if err := db.AutoMigrate(&models.Task{}); err != nil {
+	if _, err := migrations.New(db).Up(); err != nil {
 		panic("failed to migrate Task model")
 	}
 	return db
@@ -79,7 +77,7 @@ func createMultipartFileHeader(t *testing.T, fieldName, fileName string, content
 func TestCreateTask(t *testing.T) {
 	db := setupTestDB()
 	repo := repositories.NewTaskRepository(db)
-	service := services.NewTaskService(repo)
+	service := services.NewTaskService(repo, t.TempDir())
 
 	tests := []struct {
 		name        string
@@ -108,7 +106,7 @@ func TestCreateTask(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := service.CreateTask(tc.taskInput, tc.coverFile)
+			result, err := service.CreateTask(ctx, tc.taskInput, tc.coverFile)
 
 			if tc.expectError {
 				assert.Error(t, err)
@@ -159,11 +157,11 @@ func TestGetTaskByID(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			mockRepo := new(mockRepo.MockRepository)
-			taskService := services.NewTaskService(mockRepo)
+			taskService := services.NewTaskService(mockRepo, t.TempDir())
 
 			mockRepo.On("FindByID", tc.id).Return(tc.mockReturn, tc.mockError)
 
-			result, err := taskService.GetTaskByID(tc.id)
+			result, err := taskService.GetTaskByID(ctx, tc.id)
 			mockRepo.AssertExpectations(t)
 
 			if tc.expectError {
@@ -204,11 +202,11 @@ func TestGetAllTasks(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			mockRepo := new(mockRepo.MockRepository)
-			taskService := services.NewTaskService(mockRepo)
+			taskService := services.NewTaskService(mockRepo, t.TempDir())
 
 			mockRepo.On("FindAll").Return(tc.mockReturn, tc.mockError)
 
-			result, err := taskService.GetAllTasks()
+			result, err := taskService.GetAllTasks(ctx)
 			mockRepo.AssertExpectations(t)
 
 			if tc.expectError {
@@ -223,53 +221,42 @@ func TestGetAllTasks(t *testing.T) {
 }
 
 func TestUpdateTask(t *testing.T) {
+	db := setupTestDB()
+	repo := repositories.NewTaskRepository(db)
+	taskService := services.NewTaskService(repo, t.TempDir())
+
+	existing, err := repo.Create(&models.Task{Judul: "Test Judul", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+
 	tests := []struct {
 		name        string
+		id          uint
 		input       *models.Task
-		mockReturn  *models.Task
-		mockError   error
-		expectError bool
+		expectError error
 	}{
 		{
-			name: "success",
-			input: &models.Task{
-				ID:    1,
-				Judul: "Test Judul Update",
-			},
-			mockReturn: &models.Task{
-				ID:    1,
-				Judul: "Test Judul Update",
-			},
-			mockError:   nil,
-			expectError: false,
+			name:  "success",
+			id:    existing.ID,
+			input: &models.Task{Judul: "Test Judul Update"},
 		},
 		{
-			name: "error update task",
-			input: &models.Task{
-				ID:    1,
-				Judul: "Test Judul Update",
-			},
-			mockReturn:  nil,
-			mockError:   errors.New("failed update"),
-			expectError: true,
+			name:        "task not found",
+			id:          existing.ID + 1000,
+			input:       &models.Task{Judul: "Test Judul Update"},
+			expectError: services.ErrTaskNotFound,
 		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo := new(mockRepo.MockRepository)
-			taskService := services.NewTaskService(mockRepo)
-
-			mockRepo.On("Update", tc.input).Return(tc.mockReturn, tc.mockError)
-
-			result, err := taskService.UpdateTask(tc.input)
-			mockRepo.AssertExpectations(t)
+			result, err := taskService.UpdateTask(ctx, tc.id, tc.input, nil)
 
-			if tc.expectError {
-				assert.Error(t, err)
+			if tc.expectError != nil {
+				assert.ErrorIs(t, err, tc.expectError)
 				assert.Nil(t, result)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tc.mockReturn, result)
+				assert.Equal(t, tc.input.Judul, result.Judul)
+				assert.Equal(t, "Website", result.Tipe)
 			}
 		})
 	}
@@ -298,11 +285,13 @@ func TestDeleteTask(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			mockRepo := new(mockRepo.MockRepository)
-			taskService := services.NewTaskService(mockRepo)
+			taskService := services.NewTaskService(mockRepo, t.TempDir())
 
-			mockRepo.On("Delete", tc.id).Return(tc.mockReturn)
+			mockRepo.On("FindByID", tc.id).Return(&models.Task{ID: tc.id}, nil)
+			mockRepo.On("GetDB").Return(newIsolatedDB(t))
+			mockRepo.On("DeleteWithTx", tc.id, mock.Anything).Return(tc.mockReturn)
 
-			err := taskService.DeleteTask(tc.id)
+			err := taskService.DeleteTask(ctx, tc.id)
 			mockRepo.AssertExpectations(t)
 
 			if tc.expectError {
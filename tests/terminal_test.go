@@ -0,0 +1,276 @@
+package tests
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/utils"
+)
+
+// hostileName berisi karakter yang akan dieksekusi jika path sampai ke shell.
+const hostileName = `proj "a" 'b'; touch pwned; $(touch pwned2) ` + "`touch pwned3`" + ` && echo x`
+
+func hostileDir(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), hostileName)
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	return dir
+}
+
+func TestLauncherCommandPassesPathAsArgument(t *testing.T) {
+	dir := hostileDir(t)
+	for _, goos := range []string{"linux", "darwin"} {
+		launchers := utils.NewLaunchers(utils.BuiltinLaunchers(goos))
+		for _, profile := range utils.BuiltinLaunchers(goos) {
+			t.Run(goos+"/"+profile.Name, func(t *testing.T) {
+				cmd, err := launchers.Command(profile.Name, dir)
+				require.NoError(t, err)
+				assert.Equal(t, dir, cmd.Dir)
+				assert.NotContains(t, []string{"sh", "bash", "zsh"}, filepath.Base(cmd.Args[0]))
+				for _, arg := range cmd.Args {
+					if arg != dir {
+						assert.NotContains(t, arg, "pwned", "path disisipkan ke argumen lain: %q", arg)
+					}
+				}
+			})
+		}
+	}
+
+	cmd, err := utils.NewLaunchers(utils.BuiltinLaunchers("darwin")).Command("terminal", dir)
+	require.NoError(t, err)
+	assert.Equal(t, dir, cmd.Args[len(cmd.Args)-1])
+	assert.Contains(t, strings.Join(cmd.Args, " "), "quoted form of (item 1 of argv)")
+
+	for _, name := range []string{"", "xterm; touch pwned", "bash", "open -a Terminal"} {
+		_, err := utils.NewLaunchers(nil).Command(name, dir)
+		assert.ErrorIs(t, err, utils.ErrUnknownLauncher, name)
+	}
+}
+
+func TestLauncherProfilesFromConfig(t *testing.T) {
+	dir := hostileDir(t)
+	launchers := utils.NewLaunchers([]models.Launcher{
+		{Name: "vscode", Label: "VS Code", Executable: "code", Args: []string{"--new-window", "{path}"}},
+		{Name: "tmux", Executable: "tmux", Args: []string{"new-window", "-c", "{path}", "--cwd={path}"},
+			Env: map[string]string{"PROJECT_DIR": "{path}", "EDITOR": "hx"}},
+		// Nama yang sama dengan profil bawaan menggantikannya.
+		{Name: "kitty", Executable: "kitty", Args: []string{"--directory", "{path}", "hx", "."}},
+	})
+
+	cmd, err := launchers.Command("vscode", dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"code", "--new-window", dir}, cmd.Args)
+	assert.Equal(t, dir, cmd.Dir)
+	assert.Nil(t, cmd.Env, "tanpa env, environment server diwarisi apa adanya")
+
+	cmd, err = launchers.Command("tmux", dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tmux", "new-window", "-c", dir, "--cwd=" + dir}, cmd.Args)
+	assert.Subset(t, cmd.Env, []string{"EDITOR=hx", "PROJECT_DIR=" + dir})
+	assert.Contains(t, cmd.Env, "PATH="+os.Getenv("PATH"))
+
+	cmd, err = launchers.Command("kitty", dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kitty", "--directory", dir, "hx", "."}, cmd.Args)
+	kitty, ok := launchers.Get("kitty")
+	require.True(t, ok)
+	assert.Equal(t, "kitty", kitty.Title(), "label kosong memakai nama")
+
+	names := map[string]int{}
+	for _, p := range launchers.All() {
+		names[p.Name]++
+	}
+	assert.Equal(t, 1, names["kitty"])
+	assert.Equal(t, 1, names["vscode"])
+}
+
+func TestLauncherValidate(t *testing.T) {
+	valid := models.Launcher{Name: "helix-2.0_x", Executable: "hx"}
+	assert.NoError(t, valid.Validate())
+	for name, l := range map[string]models.Launcher{
+		"nama kosong":          {Executable: "code"},
+		"nama berspasi":        {Name: "vs code", Executable: "code"},
+		"huruf besar":          {Name: "VSCode", Executable: "code"},
+		"nama terlalu panjang": {Name: strings.Repeat("a", models.MaxLauncherNameLength+1), Executable: "code"},
+		"tanpa executable":     {Name: "code"},
+		"env tidak valid":      {Name: "code", Executable: "code", Env: map[string]string{"A=B": "x"}},
+	} {
+		assert.Error(t, l.Validate(), name)
+	}
+}
+
+func TestOpenLauncherDoesNotRunShell(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("terminal palsu hanya disiapkan untuk linux")
+	}
+	// kitty palsu mencatat argumennya; shell tidak pernah dipanggil sehingga
+	// file pwned tidak boleh muncul.
+	bin := t.TempDir()
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$PWD\" \"$PROJECT_DIR\" \"$@\" > \"$ARGS_FILE.tmp\" && mv \"$ARGS_FILE.tmp\" \"$ARGS_FILE\"\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "kitty"), []byte(script), 0o755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("ARGS_FILE", argsFile)
+
+	dir := hostileDir(t)
+	launchers := utils.NewLaunchers([]models.Launcher{{
+		Name: "kitty-hx", Executable: "kitty", Args: []string{"--directory", "{path}", "hx"},
+		Env: map[string]string{"PROJECT_DIR": "{path}"},
+	}})
+	require.NoError(t, launchers.Open("kitty-hx", dir))
+
+	var recorded []byte
+	require.Eventually(t, func() bool {
+		var err error
+		recorded, err = os.ReadFile(argsFile)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{dir, dir, "--directory", dir, "hx"}, strings.Split(strings.TrimSpace(string(recorded)), "\n"))
+	for _, marker := range []string{"pwned", "pwned2", "pwned3"} {
+		assert.NoFileExists(t, filepath.Join(dir, marker))
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(dir), marker))
+	}
+}
+
+func TestProjectDir(t *testing.T) {
+	repo := repositories.NewTaskRepository(newIsolatedDB(t))
+	service := services.NewTaskService(repo, t.TempDir())
+	dir := hostileDir(t)
+	file := filepath.Join(t.TempDir(), "bukan-direktori")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+
+	create := func(path *string) uint {
+		task, err := repo.Create(&models.Task{Judul: "Proyek", Tipe: "Website", Status: "todo", PathProject: path})
+		require.NoError(t, err)
+		return task.ID
+	}
+	str := func(s string) *string { return &s }
+
+	got, err := service.ProjectDir(ctx, create(str(dir+"/")))
+	require.NoError(t, err)
+	assert.Equal(t, dir, got)
+
+	_, err = service.ProjectDir(ctx, create(nil))
+	assert.ErrorIs(t, err, services.ErrNoProjectPath)
+	for _, path := range []string{filepath.Join(dir, "tidak-ada"), file, "relatif/proyek"} {
+		_, err = service.ProjectDir(ctx, create(str(path)))
+		assert.ErrorIs(t, err, services.ErrInvalidProjectPath, path)
+	}
+	_, err = service.ProjectDir(ctx, 999)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+}
+
+func TestWebSocketRejectsForeignOrigin(t *testing.T) {
+	srv := newWebSocketServer(t)
+
+	header := srv.header.Clone()
+	header.Set("Origin", "http://evil.example.com")
+	_, resp, err := websocket.DefaultDialer.Dial(srv.url, header)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	header.Set("Origin", "http"+strings.TrimPrefix(strings.TrimSuffix(srv.url, "/ws"), "ws"))
+	conn, _, err := websocket.DefaultDialer.Dial(srv.url, header)
+	require.NoError(t, err, "origin yang sama diterima")
+	conn.Close()
+}
+
+func TestWebSocketOpensOnlyStoredProjectPath(t *testing.T) {
+	srv := newWebSocketServer(t)
+	create := func(repo repositories.TaskRepository, path string) uint {
+		task, err := repo.Create(&models.Task{Judul: "Proyek", Tipe: "Website", Status: "todo", PathProject: &path})
+		require.NoError(t, err)
+		return task.ID
+	}
+	dir := hostileDir(t)
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o644))
+	valid := create(srv.repo, dir)
+	missing := create(srv.repo, filepath.Join(dir, "tidak-ada"))
+	notDir := create(srv.repo, file)
+	noPath, err := srv.repo.Create(&models.Task{Judul: "Tanpa path", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	foreign := create(srv.otherRepo, t.TempDir())
+	conn := srv.dial(t)
+
+	tests := []struct {
+		name     string
+		payload  map[string]any
+		wantCode string
+	}{
+		{"path mentah", map[string]any{"launcher": "kitty", "path": t.TempDir()}, "invalid_payload"},
+		{"launcher kosong", map[string]any{"task_id": valid}, "no_launcher"},
+		{"path tidak ada", map[string]any{"launcher": "kitty", "task_id": missing}, "invalid_project_path"},
+		{"bukan direktori", map[string]any{"launcher": "kitty", "task_id": notDir}, "invalid_project_path"},
+		{"tanpa path", map[string]any{"launcher": "kitty", "task_id": noPath.ID}, "no_project_path"},
+		{"task pengguna lain", map[string]any{"launcher": "kitty", "task_id": foreign}, "not_found"},
+		{"task tidak ada", map[string]any{"launcher": "kitty", "task_id": 999}, "not_found"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reply := sendCommand(t, conn, tc.name, "open_terminal", tc.payload)
+			assert.False(t, reply.OK)
+			require.NotNil(t, reply.Error)
+			assert.Equal(t, tc.wantCode, reply.Error.Code)
+		})
+	}
+	assert.Empty(t, srv.launches, "tidak ada terminal yang dibuka")
+
+	reply := sendCommand(t, conn, "valid", "open_terminal", map[string]any{"launcher": "kitty", "task_id": valid})
+	assert.True(t, reply.OK)
+	assert.Equal(t, launch{"kitty", dir}, <-srv.launches)
+}
+
+func TestTaskLauncherSelection(t *testing.T) {
+	repo := repositories.NewTaskRepository(newIsolatedDB(t))
+	service := services.NewTaskService(repo, t.TempDir(), services.WithLaunchers(utils.NewLaunchers([]models.Launcher{
+		{Name: "vscode", Executable: "code", Args: []string{"{path}"}},
+	})))
+
+	_, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website", Launcher: "sublime"}, nil)
+	assert.ErrorIs(t, err, services.ErrUnknownLauncher)
+
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website", Launcher: "vscode"}, nil)
+	require.NoError(t, err)
+	found, err := service.GetTaskByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "vscode", found.Launcher)
+
+	_, err = service.UpdateTask(ctx, task.ID, &models.Task{Launcher: "sublime"}, nil)
+	assert.ErrorIs(t, err, services.ErrUnknownLauncher)
+	events, err := service.GetTaskEvents(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Len(t, events, 1, "launcher yang ditolak tidak tercatat")
+}
+
+func TestWebSocketUsesTaskLauncher(t *testing.T) {
+	srv := newWebSocketServer(t)
+	dir := t.TempDir()
+	task, err := srv.repo.Create(&models.Task{Judul: "Proyek", Tipe: "Website", Status: "todo", PathProject: &dir, Launcher: "vscode"})
+	require.NoError(t, err)
+	conn := srv.dial(t)
+
+	reply := sendCommand(t, conn, "default", "open_terminal", map[string]any{"task_id": task.ID})
+	require.True(t, reply.OK, "%+v", reply.Error)
+	assert.Equal(t, "vscode", reply.Data["launcher"])
+	assert.Equal(t, launch{"vscode", dir}, <-srv.launches)
+
+	// Pilihan di modal mengalahkan launcher task; field lama "terminal"
+	// tetap diterima.
+	reply = sendCommand(t, conn, "override", "open_terminal", map[string]any{"terminal": "kitty", "task_id": task.ID})
+	require.True(t, reply.OK, "%+v", reply.Error)
+	assert.Equal(t, launch{"kitty", dir}, <-srv.launches)
+}
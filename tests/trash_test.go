@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+func TestSoftDeleteRestoreAndPurge(t *testing.T) {
+	db := newIsolatedDB(t)
+	uploads := t.TempDir()
+	service := services.NewTaskService(repositories.NewTaskRepository(db), uploads)
+	tagRepo := repositories.NewTagRepository(db)
+
+	cover := createMultipartFileHeader(t, "cover", "cover.jpg", dummyJPEG)
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website", Tags: tagList("go")}, cover)
+	require.NoError(t, err)
+	coverPath := filepath.Join(uploads, filepath.Base(task.Cover))
+	require.FileExists(t, coverPath)
+
+	require.NoError(t, service.DeleteTask(ctx, task.ID))
+
+	_, err = service.GetTaskByID(ctx, task.ID)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+	page, err := service.ListTasks(ctx, models.TaskQuery{})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+	assert.FileExists(t, coverPath, "cover disimpan sampai purge")
+
+	tags, err := tagRepo.FindAllWithCount()
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	assert.Zero(t, tags[0].TaskCount)
+
+	trash, err := service.ListTrash(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.True(t, trash[0].DeletedAt.Valid)
+	assert.Equal(t, []string{"go"}, models.TagNames(trash[0].Tags))
+
+	// Purge hanya berlaku untuk task di trash, restore sebaliknya.
+	restored, err := service.RestoreTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.False(t, restored.DeletedAt.Valid)
+	_, err = service.RestoreTask(ctx, task.ID)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+	assert.ErrorIs(t, service.PurgeTask(ctx, task.ID), services.ErrTaskNotFound)
+
+	fetched, err := service.GetTaskByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Cover, fetched.Cover)
+	assert.Equal(t, []string{"go"}, models.TagNames(fetched.Tags))
+
+	require.NoError(t, service.DeleteTask(ctx, task.ID))
+	events, err := service.GetTaskEvents(ctx, task.ID)
+	require.NoError(t, err, "riwayat task di trash tetap bisa dilihat")
+	assert.Equal(t, models.TaskEventDelete, events[0].Operation)
+	assert.Equal(t, models.TaskEventRestore, events[1].Operation)
+
+	require.NoError(t, service.PurgeTask(ctx, task.ID))
+	assert.NoFileExists(t, coverPath)
+	trash, err = service.ListTrash(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+	var joins int64
+	require.NoError(t, db.Table("task_tags").Where("task_id = ?", task.ID).Count(&joins).Error)
+	assert.Zero(t, joins)
+
+	_, err = service.GetTaskEvents(ctx, task.ID)
+	assert.ErrorIs(t, err, services.ErrTaskNotFound)
+	all, err := repositories.NewTaskRepository(db).FindEvents(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TaskEventPurge, all[0].Operation)
+}
+
+func TestTrashPurgerRemovesExpiredTasks(t *testing.T) {
+	db := newIsolatedDB(t)
+	service := services.NewTaskService(repositories.NewTaskRepository(db), t.TempDir())
+
+	var ids []uint
+	for _, judul := range []string{"lama", "baru", "aktif"} {
+		task, err := service.CreateTask(ctx, &models.Task{Judul: judul, Tipe: "Website"}, nil)
+		require.NoError(t, err)
+		ids = append(ids, task.ID)
+	}
+	require.NoError(t, service.DeleteTask(ctx, ids[0]))
+	require.NoError(t, service.DeleteTask(ctx, ids[1]))
+	require.NoError(t, db.Unscoped().Model(&models.Task{}).Where("id = ?", ids[0]).
+		UpdateColumn("deleted_at", time.Now().AddDate(0, 0, -40)).Error)
+
+	// Purger langsung berjalan sekali lalu berhenti ketika context habis.
+	runCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	services.RunTrashPurger(runCtx, service, 30*24*time.Hour, time.Hour)
+
+	trash, err := service.ListTrash(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baru"}, judulOf(trash))
+	_, err = service.GetTaskByID(ctx, ids[2])
+	assert.NoError(t, err)
+
+	// Retention nol menonaktifkan purge.
+	services.RunTrashPurger(ctx, service, 0, time.Hour)
+	n, err := service.PurgeTrashedBefore(ctx, time.Now().AddDate(0, 0, -30))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestAPITrash(t *testing.T) {
+	router, repo := newTestRouter(t)
+	task, err := repo.Create(&models.Task{Judul: "A", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+
+	rec := doJSON(t, router, http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d", task.ID), nil)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	rec = doJSON(t, router, http.MethodGet, "/api/v1/trash", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var list struct {
+		Data []models.Task `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.True(t, list.Data[0].DeletedAt.Valid)
+
+	rec = doJSON(t, router, http.MethodGet, "/trash", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), fmt.Sprintf(`action="/trash/%d/restore"`, task.ID))
+
+	rec = doJSON(t, router, http.MethodPost, fmt.Sprintf("/api/v1/trash/%d/restore", task.ID), nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = doJSON(t, router, http.MethodGet, fmt.Sprintf("/api/v1/tasks/%d", task.ID), nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = doJSON(t, router, http.MethodDelete, fmt.Sprintf("/api/v1/trash/%d", task.ID), nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	doJSON(t, router, http.MethodDelete, fmt.Sprintf("/api/v1/tasks/%d", task.ID), nil)
+	rec = doJSON(t, router, http.MethodDelete, fmt.Sprintf("/api/v1/trash/%d", task.ID), nil)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	rec = doJSON(t, router, http.MethodGet, "/trash", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Trash kosong")
+}
+
+// Menghapus task yang tidak ada atau sudah di trash lewat form HTML adalah
+// 404, bukan kegagalan server.
+func TestHTMLDeleteTaskNotFound(t *testing.T) {
+	router, repo := newTestRouter(t)
+	task, err := repo.Create(&models.Task{Judul: "A", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/task/delete/%d", task.ID)
+
+	rec := doJSON(t, router, http.MethodPost, path, nil)
+	assert.Equal(t, http.StatusSeeOther, rec.Code)
+	rec = doJSON(t, router, http.MethodPost, path, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	rec = doJSON(t, router, http.MethodPost, "/task/delete/999", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/storage"
+)
+
+func objectKeys(objects []storage.Object) []string {
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	return keys
+}
+
+// writeAged menulis file di dir dengan waktu modifikasi age yang lalu.
+func writeAged(t *testing.T, dir, name string, age time.Duration) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("x"), 0o644))
+	old := time.Now().Add(-age)
+	require.NoError(t, os.Chtimes(path, old, old))
+}
+
+func TestUploadGC(t *testing.T) {
+	service, db, coverFile, task := newCoverFixture(t)
+	uploads := filepath.Dir(coverFile)
+	trashed, err := service.CreateTask(ctx, &models.Task{Judul: "B", Tipe: "Website"},
+		createMultipartFileHeader(t, "cover", "b.jpg", encodeJPEG(t, 40, 20)))
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTask(ctx, trashed.ID))
+	require.Len(t, dirNames(t, uploads), 6)
+
+	writeAged(t, uploads, "lama.jpg", 48*time.Hour)
+	writeAged(t, uploads, ".lama.jpg.tmp-123", 48*time.Hour)
+	writeAged(t, uploads, "baru.jpg", time.Minute)
+	require.NoError(t, os.Mkdir(filepath.Join(uploads, "sub"), 0o755))
+	thumb := task.CoverVariant(models.CoverThumb).Path
+	require.NoError(t, os.Remove(filepath.Join(uploads, filepath.Base(thumb))))
+
+	gc := services.NewUploadGCService(repositories.NewTaskRepository(db),
+		storage.NewLocal(uploads, services.CoverURLPrefix))
+
+	// Dry-run melaporkan tanpa menghapus.
+	report, err := gc.Collect(ctx, services.UploadGCOptions{Grace: 24 * time.Hour, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{".lama.jpg.tmp-123", "baru.jpg", "lama.jpg"}, objectKeys(report.Orphans))
+	assert.Equal(t, []string{".lama.jpg.tmp-123", "lama.jpg"}, report.Deleted)
+	assert.Equal(t, []services.DanglingCover{{TaskID: task.ID, Path: thumb}}, report.Dangling)
+	assert.Len(t, dirNames(t, uploads), 9)
+
+	report, err = gc.Collect(ctx, services.UploadGCOptions{Grace: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, []string{".lama.jpg.tmp-123", "lama.jpg"}, report.Deleted)
+	// Cover task aktif dan task di trash tetap ada, begitu juga orphan yang
+	// belum melewati grace.
+	left := dirNames(t, uploads)
+	assert.Contains(t, left, "baru.jpg")
+	assert.NotContains(t, left, "lama.jpg")
+	for _, path := range append(task.CoverPaths(), trashed.CoverPaths()...) {
+		if path != thumb {
+			assert.Contains(t, left, filepath.Base(path))
+		}
+	}
+
+	stored, err := service.GetTaskByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.Cover, stored.Cover, "cover yang hilang hanya dilaporkan")
+}
+
+func TestUploadGCWithS3(t *testing.T) {
+	fake, srv := newFakeS3(t)
+	fake.pageSize = 2
+	s3, err := storage.NewS3(fake.config(srv.URL), services.CoverURLPrefix)
+	require.NoError(t, err)
+	db := newIsolatedDB(t)
+	service := services.NewTaskService(repositories.NewTaskRepository(db), t.TempDir(),
+		services.WithStorage(s3))
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"},
+		createMultipartFileHeader(t, "cover", "cover.jpg", encodeJPEG(t, 40, 20)))
+	require.NoError(t, err)
+
+	for _, key := range []string{"lama.jpg", "baru.jpg"} {
+		require.NoError(t, s3.Put(ctx, key, strings.NewReader("x"), "image/jpeg"))
+	}
+	obj := fake.objects["lama.jpg"]
+	obj.modTime = obj.modTime.Add(-48 * time.Hour)
+	fake.objects["lama.jpg"] = obj
+
+	// List membaca semua halaman ListObjectsV2.
+	objects, err := s3.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, fake.keys(), objectKeys(objects))
+	assert.Len(t, objects, 5)
+
+	gc := services.NewUploadGCService(repositories.NewTaskRepository(db), s3)
+	report, err := gc.Collect(ctx, services.UploadGCOptions{Grace: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"baru.jpg", "lama.jpg"}, objectKeys(report.Orphans))
+	assert.Equal(t, []string{"lama.jpg"}, report.Deleted)
+	assert.Empty(t, report.Dangling)
+	assert.NotContains(t, fake.keys(), "lama.jpg")
+	for _, path := range task.CoverPaths() {
+		assert.Contains(t, fake.keys(), storage.KeyFromURL(path))
+	}
+	assert.Zero(t, fake.rejected)
+
+	// Orphan yang gagal dihapus dilaporkan sebagai error tanpa menghentikan
+	// yang lain.
+	for _, key := range []string{"x.jpg", "y.jpg"} {
+		require.NoError(t, s3.Put(ctx, key, strings.NewReader("x"), "image/jpeg"))
+	}
+	// Enam object berarti tiga halaman list; DELETE baru.jpg sesudahnya gagal.
+	fake.failIn(4)
+	report, err = gc.Collect(ctx, services.UploadGCOptions{})
+	assert.ErrorContains(t, err, "gagal menghapus baru.jpg")
+	require.NotNil(t, report)
+	assert.Equal(t, []string{"x.jpg", "y.jpg"}, report.Deleted)
+}
+
+func TestUploadGCRunner(t *testing.T) {
+	db := newIsolatedDB(t)
+	uploads := t.TempDir()
+	writeAged(t, uploads, "lama.jpg", 48*time.Hour)
+	gc := services.NewUploadGCService(repositories.NewTaskRepository(db),
+		storage.NewLocal(uploads, services.CoverURLPrefix))
+
+	// Interval nol menonaktifkan pembersihan otomatis.
+	services.RunUploadGC(ctx, gc, time.Hour, 0)
+	assert.Equal(t, []string{"lama.jpg"}, dirNames(t, uploads))
+
+	// Runner langsung berjalan sekali lalu berhenti ketika context habis.
+	runCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	services.RunUploadGC(runCtx, gc, time.Hour, time.Hour)
+	assert.Empty(t, dirNames(t, uploads))
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/utils"
+)
+
+func encodeJPEG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	buf := new(bytes.Buffer)
+	require.NoError(t, jpeg.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil))
+	return buf.Bytes()
+}
+
+func multipartTaskRequest(t *testing.T, method, path string, cover []byte) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	require.NoError(t, writer.WriteField("judul", "Dengan cover"))
+	require.NoError(t, writer.WriteField("tipe", "Website"))
+	part, err := writer.CreateFormFile("cover", "cover.jpg")
+	require.NoError(t, err)
+	_, err = part.Write(cover)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestCoverUsesConfiguredWidthAndDir(t *testing.T) {
+	uploads := t.TempDir()
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), uploads,
+		services.WithUploadOptions(services.UploadOptions{MaxBytes: 1 << 20, CoverWidth: 32}))
+
+	cover := createMultipartFileHeader(t, "cover", "cover.jpg", encodeJPEG(t, 100, 50))
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"}, cover)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(task.Cover, "/static/uploads/tasks/"))
+
+	f, err := os.Open(filepath.Join(uploads, filepath.Base(task.Cover)))
+	require.NoError(t, err)
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	require.NoError(t, err)
+	assert.Equal(t, 32, cfg.Width)
+	assert.Equal(t, 16, cfg.Height)
+}
+
+func TestCoverTooLarge(t *testing.T) {
+	limits := services.UploadOptions{MaxBytes: 512, CoverWidth: 800}
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), t.TempDir(),
+		services.WithUploadOptions(limits))
+
+	big := encodeJPEG(t, 200, 200)
+	require.Greater(t, int64(len(big)), limits.MaxBytes)
+	cover := createMultipartFileHeader(t, "cover", "cover.jpg", big)
+	_, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"}, cover)
+	assert.ErrorIs(t, err, services.ErrCoverTooLarge)
+
+	router, _ := newTestRouter(t, services.WithUploadOptions(limits))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/api/v1/tasks", big))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(t, rec.Body.String(), "payload_too_large")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/task/add", big))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// Body yang jauh melebihi batas dihentikan sebelum selesai dibaca.
+	huge := bytes.Repeat([]byte{0xff}, 3<<20)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/api/v1/tasks", huge))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestUploadedCoverServedFromUploadDir(t *testing.T) {
+	router, _ := newTestRouter(t)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/api/v1/tasks", encodeJPEG(t, 10, 10)))
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var created apiTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+
+	rec = doJSON(t, router, http.MethodGet, created.Data.Cover, nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "image/jpeg", rec.Header().Get("Content-Type"))
+}
+
+// pngHeader adalah PNG yang hanya berisi signature dan chunk IHDR. Header
+// cukup untuk image.DecodeConfig, sehingga dimensi raksasa bisa diuji tanpa
+// membuat gambarnya.
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 17)
+	copy(ihdr, "IHDR")
+	binary.BigEndian.PutUint32(ihdr[4:], width)
+	binary.BigEndian.PutUint32(ihdr[8:], height)
+	copy(ihdr[12:], []byte{8, 2, 0, 0, 0}) // 8 bit, RGB
+	out := []byte("\x89PNG\r\n\x1a\n")
+	out = binary.BigEndian.AppendUint32(out, 13)
+	out = append(out, ihdr...)
+	return binary.BigEndian.AppendUint32(out, crc32.ChecksumIEEE(ihdr))
+}
+
+func TestCheckImage(t *testing.T) {
+	var small bytes.Buffer
+	require.NoError(t, png.Encode(&small, image.NewRGBA(image.Rect(0, 0, 300, 200))))
+
+	tests := []struct {
+		name       string
+		data       []byte
+		limits     utils.ImageLimits
+		wantFormat string
+		wantErr    error
+	}{
+		{"jpeg", encodeJPEG(t, 10, 10), utils.ImageLimits{}, "jpeg", nil},
+		{"png", small.Bytes(), utils.ImageLimits{MaxDimension: 300, MaxPixels: 60000}, "png", nil},
+		{"teks", []byte("bukan gambar, hanya teks"), utils.ImageLimits{}, "", utils.ErrUnsupportedImage},
+		{"kosong", nil, utils.ImageLimits{}, "", utils.ErrUnsupportedImage},
+		{"signature tanpa isi", []byte("\x89PNG\r\n\x1a\n"), utils.ImageLimits{}, "", utils.ErrUnsupportedImage},
+		{"svg", []byte(`<svg xmlns="http://www.w3.org/2000/svg"/>`), utils.ImageLimits{}, "", utils.ErrUnsupportedImage},
+		{"terlalu lebar", small.Bytes(), utils.ImageLimits{MaxDimension: 250}, "", utils.ErrImageTooLarge},
+		{"terlalu banyak piksel", small.Bytes(), utils.ImageLimits{MaxPixels: 50000}, "", utils.ErrImageTooLarge},
+		{"header raksasa", pngHeader(20000, 20000), utils.ImageLimits{MaxDimension: 10000}, "", utils.ErrImageTooLarge},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, format, err := utils.CheckImage(tc.data, tc.limits)
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantFormat, format)
+		})
+	}
+
+	_, _, err := utils.CheckImage(pngHeader(20000, 300), utils.ImageLimits{MaxDimension: 10000})
+	var sizeErr *utils.ImageSizeError
+	require.ErrorAs(t, err, &sizeErr)
+	assert.Equal(t, [2]int{20000, 300}, [2]int{sizeErr.Width, sizeErr.Height})
+	assert.Contains(t, err.Error(), "20000×300")
+}
+
+func TestInvalidCoverRejectedBeforeSaving(t *testing.T) {
+	uploads := t.TempDir()
+	service := services.NewTaskService(repositories.NewTaskRepository(newIsolatedDB(t)), uploads)
+
+	for name, data := range map[string][]byte{
+		"teks.jpg":    []byte("bukan gambar"),
+		"raksasa.png": pngHeader(20000, 20000),
+	} {
+		_, err := service.CreateTask(ctx, &models.Task{Judul: "A", Tipe: "Website"},
+			createMultipartFileHeader(t, "cover", name, data))
+		assert.Error(t, err, name)
+	}
+	tasks, err := service.GetAllTasks(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+	entries, err := os.ReadDir(uploads)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestInvalidCoverShownAsFieldError(t *testing.T) {
+	router, repo := newTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/task/add", []byte("bukan gambar")))
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	state := formState(t, rec.Body.String())
+	assert.Equal(t, "add", state["modal"])
+	assert.Equal(t, "Dengan cover", state["values"].(map[string]any)["judul"])
+	assert.Contains(t, state["errors"], "cover")
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, "/api/v1/tasks", pngHeader(20000, 20000)))
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	var body apiValidationBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "validation_failed", body.Error.Code)
+	assert.Equal(t, []string{"cover"}, sortedKeys(body.Error.Fields))
+
+	task, err := repo.Create(&models.Task{Judul: "Lama", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartTaskRequest(t, http.MethodPost, fmt.Sprintf("/task/update/%d", task.ID), pngHeader(20000, 20000)))
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	state = formState(t, rec.Body.String())
+	assert.Equal(t, "edit", state["modal"])
+	assert.Contains(t, state["errors"].(map[string]any)["cover"], "20000×20000")
+
+	stored, err := repo.FindByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Lama", stored.Judul)
+	assert.Equal(t, "", stored.Cover)
+}
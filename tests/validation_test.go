@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/validation"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestValidateTask(t *testing.T) {
+	manyTags := make([]models.Tag, validation.MaxTagsPerTask+1)
+	for i := range manyTags {
+		manyTags[i] = models.Tag{Name: fmt.Sprintf("tag%d", i)}
+	}
+
+	tests := []struct {
+		name    string
+		task    models.Task
+		partial bool
+		want    []string
+	}{
+		{"valid", models.Task{Judul: "A", Tipe: "Website", LinkWebsite: strPtr("https://contoh.id")}, false, nil},
+		{"kosong", models.Task{}, false, []string{"judul", "tipe"}},
+		{"kosong partial", models.Task{}, true, nil},
+		{"judul terlalu panjang", models.Task{Judul: strings.Repeat("é", validation.MaxJudulLength+1), Tipe: "Website"}, true, []string{"judul"}},
+		{"tipe tidak dikenal", models.Task{Judul: "A", Tipe: "Mobile"}, false, []string{"tipe"}},
+		{"url tanpa skema", models.Task{Judul: "A", Tipe: "Website", LinkWebsite: strPtr("contoh.id")}, false, []string{"link_website"}},
+		{"url javascript", models.Task{Judul: "A", Tipe: "Website", LinkWebsite: strPtr("javascript:alert(1)")}, false, []string{"link_website"}},
+		{"path relatif", models.Task{Judul: "A", Tipe: "Project Local", PathProject: strPtr("proyek/app")}, false, []string{"path_project"}},
+		{"catatan terlalu panjang", models.Task{Judul: "A", Tipe: "Website", Catatan: strings.Repeat("x", validation.MaxCatatanLength+1)}, false, []string{"catatan"}},
+		{"terlalu banyak tag", models.Task{Judul: "A", Tipe: "Website", Tags: manyTags}, false, []string{"tags"}},
+		{"tag terlalu panjang", models.Task{Judul: "A", Tipe: "Website", Tags: []models.Tag{{Name: strings.Repeat("t", models.MaxTagNameLength+1)}}}, false, []string{"tags"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := validation.Task(&tc.task, tc.partial)
+			fields := make([]string, 0, len(errs))
+			for field, msg := range errs {
+				assert.NotEmpty(t, msg)
+				fields = append(fields, field)
+			}
+			assert.ElementsMatch(t, tc.want, fields)
+			if len(tc.want) == 0 {
+				assert.NoError(t, errs.Err())
+			} else {
+				assert.Error(t, errs.Err())
+			}
+		})
+	}
+}
+
+type apiValidationBody struct {
+	Error struct {
+		Code   string            `json:"code"`
+		Fields map[string]string `json:"fields"`
+	} `json:"error"`
+}
+
+func TestAPIValidationFields(t *testing.T) {
+	router, repo := newTestRouter(t)
+	task, err := repo.Create(&models.Task{Judul: "A", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+
+	rec := doJSON(t, router, http.MethodPost, "/api/v1/tasks", map[string]any{
+		"tipe":         "Website",
+		"link_website": "contoh.id",
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	var body apiValidationBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, "validation_failed", body.Error.Code)
+	assert.Equal(t, []string{"judul", "link_website"}, sortedKeys(body.Error.Fields))
+
+	// PUT mengganti seluruh task sehingga judul dan tipe wajib dikirim.
+	rec = doJSON(t, router, http.MethodPut, fmt.Sprintf("/api/v1/tasks/%d", task.ID), map[string]any{
+		"path_project": "relatif",
+	})
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	body = apiValidationBody{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, []string{"judul", "path_project", "tipe"}, sortedKeys(body.Error.Fields))
+
+	rec = doJSON(t, router, http.MethodPut, fmt.Sprintf("/api/v1/tasks/%d", task.ID), map[string]any{"judul": "B", "tipe": "Website"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func multipartFields(t *testing.T, path string, fields map[string]string) *http.Request {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	require.NoError(t, writer.Close())
+	req := httptest.NewRequest(http.MethodPost, path, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+// formState mengambil JSON #task-form-state dari halaman yang dirender ulang.
+func formState(t *testing.T, page string) map[string]any {
+	t.Helper()
+	const open = `<script id="task-form-state" type="application/json">`
+	start := strings.Index(page, open)
+	require.NotEqual(t, -1, start, "halaman tidak memuat task-form-state")
+	rest := page[start+len(open):]
+	end := strings.Index(rest, "</script>")
+	require.NotEqual(t, -1, end)
+	var state map[string]any
+	require.NoError(t, json.Unmarshal([]byte(html.UnescapeString(rest[:end])), &state))
+	return state
+}
+
+func TestHTMLAddTaskRerendersWithErrors(t *testing.T) {
+	router, repo := newTestRouter(t)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartFields(t, "/task/add", map[string]string{
+		"judul":        "Tetap di form",
+		"tipe":         "Website",
+		"link_website": "bukan-url",
+		"tags":         "go, web",
+	}))
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	state := formState(t, rec.Body.String())
+	assert.Equal(t, "add", state["modal"])
+	values := state["values"].(map[string]any)
+	assert.Equal(t, "Tetap di form", values["judul"])
+	assert.Equal(t, "go, web", values["tags"])
+	errs := state["errors"].(map[string]any)
+	assert.Contains(t, errs, "link_website")
+	assert.NotContains(t, errs, "judul")
+
+	tasks, err := repo.FindAll()
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	// Halaman biasa tidak memuat state form.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), `<script id="task-form-state"`)
+}
+
+func TestHTMLUpdateTaskRerendersWithErrors(t *testing.T) {
+	router, repo := newTestRouter(t)
+	task, err := repo.Create(&models.Task{Judul: "Lama", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/task/update/%d", task.ID)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartFields(t, path, map[string]string{
+		"judul":  "",
+		"tipe":   "Website",
+		"status": "todo",
+	}))
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	state := formState(t, rec.Body.String())
+	assert.Equal(t, "edit", state["modal"])
+	assert.EqualValues(t, task.ID, state["task_id"])
+	assert.Equal(t, "todo", state["status"])
+	assert.Contains(t, state["errors"], "judul")
+
+	// Error status dari service ditampilkan di field status.
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartFields(t, path, map[string]string{
+		"judul":  "Baru",
+		"tipe":   "Website",
+		"status": "shipped",
+	}))
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	state = formState(t, rec.Body.String())
+	assert.Contains(t, state["errors"], "status")
+	assert.Equal(t, "Baru", state["values"].(map[string]any)["judul"])
+
+	stored, err := repo.FindByID(task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Lama", stored.Judul)
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, multipartFields(t, "/task/update/999", map[string]string{"judul": "", "tipe": "Website"}))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite"
+	"github.com/nabilulilalbab/welcomesite/controllers"
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/routes"
+	"github.com/nabilulilalbab/welcomesite/services"
+	"github.com/nabilulilalbab/welcomesite/utils"
+	"github.com/nabilulilalbab/welcomesite/view"
+)
+
+type launch struct{ launcher, dir string }
+
+// wsTestServer adalah router lengkap di httptest.Server dengan peluncur
+// terminal palsu dan pengguna "tester" yang sudah login.
+type wsTestServer struct {
+	url     string
+	httpURL string
+	header  http.Header
+	csrf    string
+	// otherHeader membawa cookie sesi pengguna lain.
+	otherHeader http.Header
+	// repo hanya melihat task milik tester, otherRepo milik pengguna lain.
+	repo      repositories.TaskRepository
+	otherRepo repositories.TaskRepository
+	launches  chan launch
+}
+
+func newWebSocketServer(t *testing.T, opts ...controllers.TaskControllerOption) *wsTestServer {
+	t.Helper()
+	db := newIsolatedDB(t)
+	repo := repositories.NewTaskRepository(db)
+	hub := controllers.NewHub()
+	service := services.NewTaskService(repo, t.TempDir(), services.WithNotifier(hub))
+	launches := make(chan launch, 10)
+	opts = append([]controllers.TaskControllerOption{
+		controllers.WithHub(hub),
+		controllers.WithProjectLauncher(func(launcher, dir string) error {
+			if launcher == "gagal" {
+				return utils.ErrUnknownLauncher
+			}
+			launches <- launch{launcher, dir}
+			return nil
+		}),
+	}, opts...)
+	taskCtrl := controllers.NewTaskController(service, view.ParseTemplates(), opts...)
+	auth := services.NewAuthService(repositories.NewUserRepository(db))
+	router := routes.NewRouter(taskCtrl, controllers.NewTaskAPIController(service),
+		controllers.NewTagAPIController(services.NewTagService(repositories.NewTagRepository(db))),
+		controllers.NewAuthController(auth, view.ParseTemplates(), false),
+		welcomesite.StaticFS)
+	user, token := loginTestUser(t, auth, "tester")
+	other, otherToken := loginTestUser(t, auth, "lain")
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return &wsTestServer{
+		url:         "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws",
+		httpURL:     srv.URL,
+		header:      http.Header{"Cookie": {controllers.SessionCookieName + "=" + token}},
+		csrf:        auth.CSRFToken(token),
+		otherHeader: http.Header{"Cookie": {controllers.SessionCookieName + "=" + otherToken}},
+		repo:        repo.WithOwner(user.ID),
+		otherRepo:   repo.WithOwner(other.ID),
+		launches:    launches,
+	}
+}
+
+func (s *wsTestServer) dial(t *testing.T) *websocket.Conn {
+	t.Helper()
+	return s.dialAs(t, s.header)
+}
+
+func (s *wsTestServer) dialAs(t *testing.T, header http.Header) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(s.url, header)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// do mengirim request HTTP sebagai tester lewat server yang sama dengan
+// WebSocket-nya.
+func (s *wsTestServer) do(t *testing.T, method, path string, body any) *http.Response {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req, err := http.NewRequest(method, s.httpURL+path, &buf)
+	require.NoError(t, err)
+	req.Header = s.header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(controllers.CSRFHeaderName, s.csrf)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+type wsReply struct {
+	Type  string         `json:"type"`
+	ID    string         `json:"id"`
+	OK    bool           `json:"ok"`
+	Data  map[string]any `json:"data"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func readReply(t *testing.T, conn *websocket.Conn) wsReply {
+	t.Helper()
+	var reply wsReply
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, conn.ReadJSON(&reply))
+	return reply
+}
+
+func sendCommand(t *testing.T, conn *websocket.Conn, id, typ string, payload any) wsReply {
+	t.Helper()
+	require.NoError(t, conn.WriteJSON(map[string]any{"type": typ, "id": id, "payload": payload}))
+	reply := readReply(t, conn)
+	assert.Equal(t, id, reply.ID, "balasan membawa ID request")
+	return reply
+}
+
+func TestWebSocketAcknowledgesEveryMessage(t *testing.T) {
+	srv := newWebSocketServer(t)
+	path := t.TempDir()
+	task, err := srv.repo.Create(&models.Task{Judul: "Proyek", Tipe: "Website", Status: "todo", PathProject: &path})
+	require.NoError(t, err)
+	conn := srv.dial(t)
+
+	reply := sendCommand(t, conn, "req-1", "open_terminal", map[string]any{"launcher": "kitty", "task_id": task.ID})
+	assert.Equal(t, "ack", reply.Type)
+	assert.True(t, reply.OK)
+	assert.Nil(t, reply.Error)
+	assert.Equal(t, "kitty", reply.Data["launcher"])
+	assert.EqualValues(t, task.ID, reply.Data["task_id"])
+	assert.Equal(t, launch{"kitty", path}, <-srv.launches)
+
+	tests := []struct {
+		name     string
+		message  string
+		wantID   string
+		wantCode string
+	}{
+		{"bukan json", `{"type":`, "", "invalid_json"},
+		{"tipe tidak dikenal", `{"type":"hapus_semua","id":"req-2"}`, "req-2", "unknown_type"},
+		{"payload kosong", `{"type":"open_terminal","id":"req-3"}`, "req-3", "invalid_payload"},
+		{"payload salah tipe", `{"type":"open_terminal","id":"req-4","payload":{"task_id":"1"}}`, "req-4", "invalid_payload"},
+		{"launcher ditolak peluncur", `{"type":"open_terminal","id":"req-5","payload":{"launcher":"gagal","task_id":` +
+			fmt.Sprint(task.ID) + `}}`, "req-5", "unknown_launcher"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(tc.message)))
+			reply := readReply(t, conn)
+			assert.Equal(t, "error", reply.Type)
+			assert.Equal(t, tc.wantID, reply.ID)
+			assert.False(t, reply.OK)
+			require.NotNil(t, reply.Error)
+			assert.Equal(t, tc.wantCode, reply.Error.Code)
+			assert.NotEmpty(t, reply.Error.Message)
+		})
+	}
+
+	// Koneksi tetap dipakai setelah pesan yang salah.
+	reply = sendCommand(t, conn, "req-6", "open_terminal", map[string]any{"launcher": "xterm", "task_id": task.ID})
+	assert.True(t, reply.OK)
+}
+
+func TestWebSocketSendsPings(t *testing.T) {
+	srv := newWebSocketServer(t, controllers.WithWebSocketPongWait(200*time.Millisecond))
+	conn := srv.dial(t)
+
+	pings := make(chan struct{}, 10)
+	conn.SetPingHandler(func(data string) error {
+		pings <- struct{}{}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	// Ping dan close frame hanya diproses selama klien membaca.
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Klien yang membalas pong tetap terhubung melewati beberapa pongWait.
+	for i := 0; i < 4; i++ {
+		select {
+		case <-pings:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("ping ke-%d tidak diterima", i+1)
+		}
+	}
+}
+
+func TestWebSocketClosesUnresponsiveClient(t *testing.T) {
+	srv := newWebSocketServer(t, controllers.WithWebSocketPongWait(200*time.Millisecond))
+	conn := srv.dial(t)
+	// Klien tidak membalas ping, sehingga read deadline server habis.
+	conn.SetPingHandler(func(string) error { return nil })
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err := conn.ReadMessage()
+	require.Error(t, err)
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		assert.False(t, netErr.Timeout(), "server yang harus menutup koneksi: %v", err)
+	}
+}
+
+func TestWebSocketRejectsOversizedMessage(t *testing.T) {
+	srv := newWebSocketServer(t)
+	conn := srv.dial(t)
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 64<<10))))
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseMessageTooBig), "%v", err)
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/nabilulilalbab/welcomesite/config"
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/repositories"
+	"github.com/nabilulilalbab/welcomesite/services"
+)
+
+func reviewWorkflow() *models.Workflow {
+	return &models.Workflow{
+		Initial: "todo",
+		Statuses: []models.StatusDefinition{
+			{Key: "todo"}, {Key: "inprogress"}, {Key: "review"}, {Key: "done", Done: true},
+		},
+		Transitions: map[string][]string{
+			"todo":       {"inprogress"},
+			"inprogress": {"review"},
+			"review":     {"inprogress", "done"},
+		},
+	}
+}
+
+func TestWorkflowValidate(t *testing.T) {
+	require.NoError(t, reviewWorkflow().Validate())
+	require.NoError(t, models.DefaultWorkflow().Validate())
+
+	broken := []func(*models.Workflow){
+		func(w *models.Workflow) { w.Initial = "backlog" },
+		func(w *models.Workflow) { w.Statuses = append(w.Statuses, models.StatusDefinition{Key: "todo"}) },
+		func(w *models.Workflow) { w.Transitions["done"] = []string{"archived"} },
+		func(w *models.Workflow) { w.Transitions["archived"] = []string{"todo"} },
+		func(w *models.Workflow) { w.Statuses = nil },
+	}
+	for i, mutate := range broken {
+		wf := reviewWorkflow()
+		mutate(wf)
+		assert.Error(t, wf.Validate(), "case %d", i)
+	}
+}
+
+func TestLoadWorkflow(t *testing.T) {
+	wf, err := config.LoadWorkflow(filepath.Join(t.TempDir(), "tidak-ada.json"))
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultWorkflow(), wf)
+
+	wf, err = config.LoadWorkflow("../workflow.example.json")
+	require.NoError(t, err)
+	assert.True(t, wf.CanTransition("review", "done"))
+	assert.False(t, wf.CanTransition("todo", "done"))
+
+	path := filepath.Join(t.TempDir(), "workflow.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"initial":"x","statuses":[{"key":"todo"}]}`), 0o644))
+	_, err = config.LoadWorkflow(path)
+	assert.Error(t, err)
+}
+
+func TestUpdateTaskEnforcesTransitions(t *testing.T) {
+	repo := repositories.NewTaskRepository(newIsolatedDB(t))
+	service := services.NewTaskService(repo, t.TempDir(), services.WithWorkflow(reviewWorkflow()))
+
+	task, err := service.CreateTask(ctx, &models.Task{Judul: "Fitur", Tipe: "Website"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "todo", task.Status)
+
+	_, err = service.UpdateTask(ctx, task.ID, &models.Task{Status: "done"}, nil)
+	var transitionErr *services.TransitionError
+	require.ErrorAs(t, err, &transitionErr)
+	assert.Equal(t, "todo", transitionErr.From)
+	assert.Equal(t, "done", transitionErr.To)
+	assert.ErrorIs(t, err, services.ErrIllegalTransition)
+
+	_, err = service.UpdateTask(ctx, task.ID, &models.Task{Status: "shipped"}, nil)
+	assert.ErrorIs(t, err, services.ErrUnknownStatus)
+
+	for _, status := range []string{"inprogress", "review", "done"} {
+		updated, err := service.UpdateTask(ctx, task.ID, &models.Task{Status: status}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, status, updated.Status)
+	}
+
+	_, err = service.CreateTask(ctx, &models.Task{Judul: "x", Tipe: "Website", Status: "shipped"}, nil)
+	assert.ErrorIs(t, err, services.ErrUnknownStatus)
+}
+
+func TestAPITransitionErrors(t *testing.T) {
+	router, repo := newTestRouter(t, services.WithWorkflow(reviewWorkflow()))
+	task, err := repo.Create(&models.Task{Judul: "A", Tipe: "Website", Status: "todo"})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/api/v1/tasks/%d", task.ID)
+
+	rec := doJSON(t, router, http.MethodPut, path, map[string]any{"judul": "A", "tipe": "Website", "status": "done"})
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), "illegal_transition")
+
+	rec = doJSON(t, router, http.MethodPut, path, map[string]any{"judul": "A", "tipe": "Website", "status": "shipped"})
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unknown_status")
+
+	rec = doJSON(t, router, http.MethodGet, "/api/v1/workflow", nil)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"initial":"todo"`)
+}
@@ -1,83 +1,130 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
-	"strings"
+
+	"github.com/nabilulilalbab/welcomesite/models"
 )
 
-type TerminalOption struct {
-	Name    string `json:"name"`
-	Command string `json:"command"`
-}
+// ErrUnknownLauncher dikembalikan Launchers untuk nama profil yang tidak
+// terdaftar.
+var ErrUnknownLauncher = errors.New("launcher tidak dikenal")
 
 func isCommandAvailable(command string) bool {
 	_, err := exec.LookPath(command)
 	return err == nil
 }
 
-func GetAvailableTerminals() []TerminalOption {
-	platform := runtime.GOOS
-	var terminals []TerminalOption
-
-	switch platform {
+// BuiltinLaunchers adalah profil bawaan per platform: terminal yang membuka
+// nvim di direktori proyek.
+func BuiltinLaunchers(goos string) []models.Launcher {
+	switch goos {
 	case "linux":
-		terminals = []TerminalOption{
-			{"Kitty", "kitty"},
-			{"GNOME Terminal", "gnome-terminal"},
-			{"Konsole", "konsole"},
-			{"XTerm", "xterm"},
-			{"Alacritty", "alacritty"},
-			{"Terminator", "terminator"},
+		return []models.Launcher{
+			{Name: "kitty", Label: "Kitty", Executable: "kitty", Args: []string{"--directory", "{path}", "nvim"}},
+			{Name: "gnome-terminal", Label: "GNOME Terminal", Executable: "gnome-terminal", Args: []string{"--working-directory", "{path}", "--", "nvim"}},
+			{Name: "konsole", Label: "Konsole", Executable: "konsole", Args: []string{"--workdir", "{path}", "-e", "nvim"}},
+			// xterm tidak punya opsi direktori kerja; cukup jalankan di dir.
+			{Name: "xterm", Label: "XTerm", Executable: "xterm", Args: []string{"-e", "nvim"}},
+			{Name: "alacritty", Label: "Alacritty", Executable: "alacritty", Args: []string{"--working-directory", "{path}", "-e", "nvim"}},
+			{Name: "terminator", Label: "Terminator", Executable: "terminator", Args: []string{"--working-directory", "{path}", "-x", "nvim"}},
 		}
 	case "darwin":
-		terminals = []TerminalOption{
-			{"iTerm2", "open -a iTerm"},
-			{"Terminal", "open -a Terminal"},
-			{"Kitty", "kitty"},
-			{"Alacritty", "alacritty"},
+		return []models.Launcher{
+			{Name: "iterm", Label: "iTerm2", Executable: "open", Args: []string{"-a", "iTerm", "{path}"}},
+			// Path diteruskan sebagai argv skrip dan di-quote oleh AppleScript
+			// sebelum sampai ke shell Terminal.
+			{Name: "terminal", Label: "Terminal", Executable: "osascript", Args: []string{
+				"-e", "on run argv",
+				"-e", `tell application "Terminal" to do script "cd " & quoted form of (item 1 of argv) & " && nvim"`,
+				"-e", "end run",
+				"{path}",
+			}},
+			{Name: "kitty", Label: "Kitty", Executable: "kitty", Args: []string{"--directory", "{path}", "nvim"}},
+			{Name: "alacritty", Label: "Alacritty", Executable: "alacritty", Args: []string{"--working-directory", "{path}", "-e", "nvim"}},
 		}
-	default:
-		return []TerminalOption{}
 	}
+	return nil
+}
+
+// Launchers adalah daftar profil launcher yang boleh dipakai membuka
+// proyek. Hanya profil di daftar ini yang bisa dijalankan; klien cukup
+// menyebut namanya.
+type Launchers struct {
+	profiles []models.Launcher
+}
 
-	var available []TerminalOption
-	for _, term := range terminals {
-		if isCommandAvailable(strings.Split(term.Command, " ")[0]) {
-			available = append(available, term)
+// NewLaunchers menggabungkan profil bawaan platform ini dengan profil dari
+// konfigurasi. Profil konfigurasi yang namanya sama menggantikan profil
+// bawaan. Profil diasumsikan sudah divalidasi.
+func NewLaunchers(custom []models.Launcher) *Launchers {
+	l := &Launchers{}
+	index := make(map[string]int)
+	for _, p := range append(BuiltinLaunchers(runtime.GOOS), custom...) {
+		if i, ok := index[p.Name]; ok {
+			l.profiles[i] = p
+			continue
 		}
+		index[p.Name] = len(l.profiles)
+		l.profiles = append(l.profiles, p)
 	}
-	return available
+	return l
 }
 
-func OpenTerminal(terminalCmd, path string) error {
-	platform := runtime.GOOS
-	var cmd *exec.Cmd
+// Get mengembalikan profil dengan nama tersebut.
+func (l *Launchers) Get(name string) (models.Launcher, bool) {
+	for _, p := range l.profiles {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return models.Launcher{}, false
+}
 
-	switch platform {
-	case "linux":
-		switch {
-		case strings.Contains(terminalCmd, "kitty"):
-			cmd = exec.Command("kitty", "--directory", path, "nvim")
-		case strings.Contains(terminalCmd, "gnome-terminal"):
-			cmd = exec.Command("gnome-terminal", "--working-directory", path, "--", "nvim")
-		case strings.Contains(terminalCmd, "konsole"):
-			cmd = exec.Command("konsole", "--workdir", path, "-e", "nvim")
-		case strings.Contains(terminalCmd, "alacritty"):
-			cmd = exec.Command("alacritty", "--working-directory", path, "-e", "nvim")
-		case strings.Contains(terminalCmd, "terminator"):
-			cmd = exec.Command("terminator", "--working-directory", path, "-x", "nvim")
-		default:
-			cmd = exec.Command("xterm", "-e", fmt.Sprintf("cd %s && nvim", path))
+// All mengembalikan semua profil, termasuk yang executable-nya tidak
+// terpasang.
+func (l *Launchers) All() []models.Launcher {
+	return append([]models.Launcher(nil), l.profiles...)
+}
+
+// Available mengembalikan profil yang executable-nya ditemukan di PATH.
+func (l *Launchers) Available() []models.Launcher {
+	available := []models.Launcher{}
+	for _, p := range l.profiles {
+		if isCommandAvailable(p.Executable) {
+			available = append(available, p)
 		}
-	case "darwin":
-		script := fmt.Sprintf(`tell application "Terminal" to do script "cd %s && nvim"`, path)
-		cmd = exec.Command("osascript", "-e", script)
-	default:
-		return fmt.Errorf("platform tidak didukung: %s", platform)
 	}
+	return available
+}
 
+// Command menyusun perintah profil name untuk dir. dir hanya masuk lewat
+// placeholder {path} sebagai bagian dari argv atau env dan tidak pernah
+// melewati shell, sehingga karakter seperti ; $ ` atau tanda kutip di
+// dalamnya tidak dieksekusi.
+func (l *Launchers) Command(name, dir string) (*exec.Cmd, error) {
+	profile, ok := l.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLauncher, name)
+	}
+	cmd := exec.Command(profile.Executable, profile.Argv(dir)...)
+	cmd.Dir = dir
+	if len(profile.Env) > 0 {
+		cmd.Env = append(os.Environ(), profile.Environ(dir)...)
+	}
+	return cmd, nil
+}
+
+// Open menjalankan profil name di dir tanpa menunggu programnya ditutup.
+func (l *Launchers) Open(name, dir string) error {
+	cmd, err := l.Command(name, dir)
+	if err != nil {
+		return err
+	}
 	if err := cmd.Start(); err != nil {
 		return err
 	}
@@ -86,4 +133,4 @@ func OpenTerminal(terminalCmd, path string) error {
 
 func GetOS() string {
 	return runtime.GOOS
-}
\ No newline at end of file
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+)
+
+// ErrGitUnavailable dikembalikan ReadGitStatus jika perintah git tidak
+// ditemukan di PATH.
+var ErrGitUnavailable = errors.New("git tidak tersedia")
+
+// gitCommand menjalankan git di dir tanpa shell. fsmonitor dimatikan karena
+// konfigurasi repositori bisa mengisinya dengan program sembarang, dan
+// --no-optional-locks mencegah status mengunci index yang sedang dipakai
+// pengguna.
+func gitCommand(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	base := []string{"--no-optional-locks", "-c", "core.fsmonitor=false", "-C", dir}
+	cmd := exec.CommandContext(ctx, "git", append(base, args...)...)
+	cmd.Env = append(os.Environ(), "LC_ALL=C", "GIT_TERMINAL_PROMPT=0")
+	return cmd
+}
+
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	out, err := gitCommand(ctx, dir, args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git %s: %w: %s", args[0], err, bytes.TrimSpace(exitErr.Stderr))
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
+
+// ReadGitStatus menjalankan git di dir dan merangkum hasilnya. Direktori
+// yang bukan working tree git menghasilkan GitStatus dengan Repo false,
+// bukan error.
+func ReadGitStatus(ctx context.Context, dir string) (*models.GitStatus, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrGitUnavailable, err)
+	}
+	status := &models.GitStatus{CheckedAt: time.Now()}
+	out, err := runGit(ctx, dir, "rev-parse", "--is-inside-work-tree")
+	if err != nil {
+		if ctx.Err() == nil && strings.Contains(err.Error(), "not a git repository") {
+			return status, nil
+		}
+		return nil, err
+	}
+	// "false" berarti dir berada di dalam direktori .git.
+	if strings.TrimSpace(string(out)) != "true" {
+		return status, nil
+	}
+	status.Repo = true
+
+	out, err = runGit(ctx, dir, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return nil, err
+	}
+	if hasCommits := parseGitStatus(out, status); !hasCommits {
+		return status, nil
+	}
+
+	out, err = runGit(ctx, dir, "log", "-1", "--format=%H%x00%cI%x00%s")
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.SplitN(strings.TrimRight(string(out), "\n"), "\x00", 3)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("git log: keluaran tidak dikenal %q", out)
+	}
+	date, err := time.Parse(time.RFC3339, fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("git log: tanggal commit: %w", err)
+	}
+	status.LastCommit = &models.GitCommit{Hash: fields[0], Message: fields[2], Date: date}
+	return status, nil
+}
+
+// parseGitStatus membaca keluaran `git status --porcelain=v2 --branch` ke
+// status. Hasilnya false jika repositori belum punya commit.
+func parseGitStatus(out []byte, status *models.GitStatus) (hasCommits bool) {
+	hasCommits = true
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		header, ok := strings.CutPrefix(line, "# ")
+		if !ok {
+			// Baris 1, 2 dan u adalah file yang berubah, ? file yang belum
+			// dilacak.
+			if line != "" && strings.ContainsRune("12u?", rune(line[0])) {
+				status.Dirty++
+			}
+			continue
+		}
+		key, value, _ := strings.Cut(header, " ")
+		switch key {
+		case "branch.oid":
+			hasCommits = value != "(initial)"
+		case "branch.head":
+			if value != "(detached)" {
+				status.Branch = value
+			}
+		case "branch.upstream":
+			status.Upstream = value
+		case "branch.ab":
+			var ahead, behind string
+			if _, err := fmt.Sscan(value, &ahead, &behind); err == nil {
+				status.Ahead, _ = strconv.Atoi(strings.TrimPrefix(ahead, "+"))
+				status.Behind, _ = strconv.Atoi(strings.TrimPrefix(behind, "-"))
+			}
+		}
+	}
+	return hasCommits
+}
@@ -0,0 +1,306 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nfnt/resize"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	// ErrUnsupportedImage dikembalikan ketika isi file bukan gambar yang bisa
+	// didecode. Format dikenali dari isi file, bukan dari nama filenya.
+	ErrUnsupportedImage = errors.New("format gambar tidak didukung")
+	// ErrImageTooLarge cocok (errors.Is) dengan setiap *ImageSizeError.
+	ErrImageTooLarge = errors.New("dimensi gambar terlalu besar")
+)
+
+// ImageLimits membatasi dimensi gambar yang boleh didecode penuh. Nilai 0
+// berarti tidak dibatasi.
+type ImageLimits struct {
+	// MaxDimension adalah lebar dan tinggi maksimum, dalam piksel.
+	MaxDimension int
+	// MaxPixels adalah jumlah piksel maksimum (lebar × tinggi).
+	MaxPixels int
+}
+
+// ImageSizeError dikembalikan CheckImage ketika dimensi dari header gambar
+// melewati ImageLimits.
+type ImageSizeError struct {
+	Width  int
+	Height int
+	Limits ImageLimits
+}
+
+func (e *ImageSizeError) Error() string {
+	if limit := e.Limits.MaxDimension; limit > 0 && (e.Width > limit || e.Height > limit) {
+		return fmt.Sprintf("gambar %d×%d piksel melebihi batas %d piksel per sisi", e.Width, e.Height, limit)
+	}
+	return fmt.Sprintf("gambar %d×%d piksel melebihi batas %d piksel", e.Width, e.Height, e.Limits.MaxPixels)
+}
+
+func (e *ImageSizeError) Is(target error) bool {
+	return target == ErrImageTooLarge
+}
+
+// imageSignatures adalah magic byte format yang didukung, dengan nama format
+// seperti yang dipakai package image.
+var imageSignatures = []struct {
+	format string
+	match  func([]byte) bool
+}{
+	{"jpeg", prefix("\xff\xd8\xff")},
+	{"png", prefix("\x89PNG\r\n\x1a\n")},
+	{"gif", func(b []byte) bool { return prefix("GIF87a")(b) || prefix("GIF89a")(b) }},
+	{"webp", func(b []byte) bool { return len(b) >= 12 && string(b[:4]) == "RIFF" && string(b[8:12]) == "WEBP" }},
+	{"bmp", prefix("BM")},
+	{"tiff", func(b []byte) bool { return prefix("II*\x00")(b) || prefix("MM\x00*")(b) }},
+}
+
+func prefix(p string) func([]byte) bool {
+	return func(b []byte) bool { return bytes.HasPrefix(b, []byte(p)) }
+}
+
+// SniffImage mengenali format gambar dari magic byte di awal data.
+func SniffImage(data []byte) (string, error) {
+	for _, sig := range imageSignatures {
+		if sig.match(data) {
+			return sig.format, nil
+		}
+	}
+	return "", fmt.Errorf("%w: hanya JPEG, PNG, GIF, WebP, BMP atau TIFF", ErrUnsupportedImage)
+}
+
+// CheckImage memastikan data adalah gambar yang didukung dan dimensinya di
+// dalam limits. Hanya header yang dibaca (image.DecodeConfig), sehingga
+// gambar raksasa ditolak sebelum pikselnya memakan memori.
+func CheckImage(data []byte, limits ImageLimits) (image.Config, string, error) {
+	format, err := SniffImage(data)
+	if err != nil {
+		return image.Config{}, "", err
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 {
+		return image.Config{}, "", fmt.Errorf("%w: file %s rusak", ErrUnsupportedImage, strings.ToUpper(format))
+	}
+	tooWide := limits.MaxDimension > 0 && (cfg.Width > limits.MaxDimension || cfg.Height > limits.MaxDimension)
+	tooMany := limits.MaxPixels > 0 && cfg.Width*cfg.Height > limits.MaxPixels
+	if tooWide || tooMany {
+		return image.Config{}, "", &ImageSizeError{Width: cfg.Width, Height: cfg.Height, Limits: limits}
+	}
+	return cfg, format, nil
+}
+
+// ImageVariant adalah satu ukuran yang dibuat EncodeImageVariants. Gambar
+// yang lebih sempit dari Width tidak diperbesar.
+type ImageVariant struct {
+	Name  string
+	Width uint
+}
+
+// EncodedImage adalah satu varian hasil EncodeImageVariants.
+type EncodedImage struct {
+	Name string
+	// Ext adalah ekstensi file, termasuk titik: .jpg atau .png.
+	Ext         string
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+// EncodeImageVariants memeriksa data dengan CheckImage, mendecodenya,
+// memutar JPEG sesuai orientasi EXIF, lalu meng-encode setiap varian
+// sebagai JPEG, atau PNG jika gambar punya piksel transparan.
+func EncodeImageVariants(data []byte, variants []ImageVariant, limits ImageLimits) ([]EncodedImage, error) {
+	if _, _, err := CheckImage(data, limits); err != nil {
+		return nil, err
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	ext, contentType, encode := ".jpg", "image/jpeg", func(w io.Writer, m image.Image) error {
+		return jpeg.Encode(w, m, &jpeg.Options{Quality: 85})
+	}
+	if !isOpaque(img) {
+		ext, contentType, encode = ".png", "image/png", png.Encode
+	}
+
+	encoded := make([]EncodedImage, 0, len(variants))
+	for _, variant := range variants {
+		m := img
+		if variant.Width > 0 && uint(img.Bounds().Dx()) > variant.Width {
+			m = resize.Resize(variant.Width, 0, img, resize.Lanczos3)
+		}
+		var buf bytes.Buffer
+		if err := encode(&buf, m); err != nil {
+			return nil, err
+		}
+		b := m.Bounds()
+		encoded = append(encoded, EncodedImage{
+			Name:        variant.Name,
+			Ext:         ext,
+			ContentType: contentType,
+			Data:        buf.Bytes(),
+			Width:       b.Dx(),
+			Height:      b.Dy(),
+		})
+	}
+	return encoded, nil
+}
+
+// WriteFileAtomic menulis file lewat file sementara di direktori yang sama
+// lalu me-rename-nya ke path. Pembaca tidak pernah melihat file yang baru
+// setengah ditulis, dan jika write gagal tidak ada file yang tertinggal.
+func WriteFileAtomic(path string, write func(io.Writer) error) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+	if err = write(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	// File sementara dibuat dengan mode 0600; cover disajikan oleh server
+	// web sehingga disamakan dengan os.Create.
+	if err = os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+// jpegOrientation membaca tag Orientation (0x0112) dari segmen EXIF APP1.
+// Hasilnya 1 (tidak perlu diputar) jika tag tidak ada atau tidak terbaca.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Data gambar dimulai; EXIF selalu ada sebelumnya.
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// exifOrientation mencari tag Orientation di IFD pertama blok TIFF EXIF.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < entries; k++ {
+		entry := ifd + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if v := int(order.Uint16(tiff[entry+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation membalik atau memutar img supaya tampil tegak sesuai
+// nilai Orientation EXIF (1-8).
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // cermin horizontal
+				dx, dy = w-1-x, y
+			case 3: // putar 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // cermin vertikal
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // putar 90° searah jarum jam
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // putar 90° berlawanan jarum jam
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
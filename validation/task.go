@@ -0,0 +1,92 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+)
+
+// Batas panjang input task.
+const (
+	MaxJudulLength   = 255
+	MaxCatatanLength = 5000
+	MaxPathLength    = 4096
+	MaxURLLength     = 2048
+	MaxTagsPerTask   = 20
+)
+
+// TaskTypes adalah nilai Tipe yang dikenal.
+var TaskTypes = []string{"Project Local", "Website"}
+
+// Task memeriksa input task. Jika partial, field kosong berarti "tidak
+// diubah" sehingga Judul dan Tipe tidak wajib. Status dan launcher diperiksa service karena bergantung pada
+// workflow dan konfigurasi.
+func Task(task *models.Task, partial bool) Errors {
+	errs := Errors{}
+	required := func(value string) string {
+		if partial {
+			return ""
+		}
+		return Required(value)
+	}
+	errs.Check("judul", required(task.Judul), MaxLength(task.Judul, MaxJudulLength))
+	errs.Check("tipe", required(task.Tipe), OneOf(task.Tipe, TaskTypes...))
+	if task.PathProject != nil {
+		errs.Check("path_project", MaxLength(*task.PathProject, MaxPathLength), AbsolutePath(*task.PathProject))
+	}
+	if task.LinkWebsite != nil {
+		errs.Check("link_website", MaxLength(*task.LinkWebsite, MaxURLLength), HTTPURL(*task.LinkWebsite))
+	}
+	errs.Check("catatan", MaxLength(task.Catatan, MaxCatatanLength))
+	errs.Check("tags", tags(models.TagNames(task.Tags)))
+	return errs
+}
+
+// TaskPatch memeriksa field yang Set di patch. Judul, tipe dan status tidak
+// boleh dikosongkan; field lain boleh.
+func TaskPatch(patch *models.TaskPatch) Errors {
+	errs := Errors{}
+	if patch.Judul.Set {
+		errs.Check("judul", Required(patch.Judul.Value), MaxLength(patch.Judul.Value, MaxJudulLength))
+	}
+	if patch.Tipe.Set {
+		errs.Check("tipe", Required(patch.Tipe.Value), OneOf(patch.Tipe.Value, TaskTypes...))
+	}
+	if patch.Status.Set {
+		errs.Check("status", Required(patch.Status.Value))
+	}
+	if patch.PathProject.Set {
+		errs.Check("path_project", MaxLength(patch.PathProject.Value, MaxPathLength), AbsolutePath(patch.PathProject.Value))
+	}
+	if patch.LinkWebsite.Set {
+		errs.Check("link_website", MaxLength(patch.LinkWebsite.Value, MaxURLLength), HTTPURL(patch.LinkWebsite.Value))
+	}
+	if patch.Catatan.Set {
+		errs.Check("catatan", MaxLength(patch.Catatan.Value, MaxCatatanLength))
+	}
+	if patch.Tags.Set {
+		errs.Check("tags", tags(patch.Tags.Value))
+	}
+	return errs
+}
+
+func tags(names []string) string {
+	names = models.NormalizeTagNames(names)
+	if len(names) > MaxTagsPerTask {
+		return fmt.Sprintf("maksimal %d tag", MaxTagsPerTask)
+	}
+	for _, name := range names {
+		if msg := MaxLength(name, models.MaxTagNameLength); msg != "" {
+			return fmt.Sprintf("tag %q %s", truncate(name, 20), msg)
+		}
+	}
+	return ""
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return strings.TrimSpace(string(r[:n])) + "..."
+	}
+	return s
+}
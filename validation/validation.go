@@ -0,0 +1,105 @@
+// Package validation memeriksa input pengguna sebelum sampai ke service dan
+// mengumpulkan pesan error per field, sehingga form HTML dan API JSON
+// memakai aturan dan pesan yang sama.
+package validation
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Errors memetakan nama field (sama dengan nama input form dan field JSON)
+// ke pesan error pertamanya.
+type Errors map[string]string
+
+// Check mencatat pesan pertama yang tidak kosong dari messages untuk field.
+// Field yang sudah punya error tidak ditimpa.
+func (e Errors) Check(field string, messages ...string) {
+	if _, ok := e[field]; ok {
+		return
+	}
+	for _, message := range messages {
+		if message != "" {
+			e[field] = message
+			return
+		}
+	}
+}
+
+func (e Errors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field := range e {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		parts[i] = field + ": " + e[field]
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Err mengembalikan nil jika tidak ada error, supaya Errors kosong tidak
+// terbaca sebagai error yang tidak nil.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Aturan di bawah mengembalikan pesan error, atau string kosong jika value
+// valid. Selain Required, value kosong selalu valid.
+
+func Required(value string) string {
+	if strings.TrimSpace(value) == "" {
+		return "wajib diisi"
+	}
+	return ""
+}
+
+// MaxLength menghitung karakter, bukan byte.
+func MaxLength(value string, max int) string {
+	if utf8.RuneCountInString(value) > max {
+		return fmt.Sprintf("maksimal %d karakter", max)
+	}
+	return ""
+}
+
+func OneOf(value string, allowed ...string) string {
+	if value == "" {
+		return ""
+	}
+	for _, a := range allowed {
+		if value == a {
+			return ""
+		}
+	}
+	return "harus salah satu dari: " + strings.Join(allowed, ", ")
+}
+
+// HTTPURL menerima URL absolut dengan skema http atau https dan host.
+func HTTPURL(value string) string {
+	if value == "" {
+		return ""
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "harus URL lengkap yang diawali http:// atau https://"
+	}
+	return ""
+}
+
+func AbsolutePath(value string) string {
+	if value == "" {
+		return ""
+	}
+	if !filepath.IsAbs(value) {
+		return "harus path absolut, misalnya /home/user/proyek"
+	}
+	return ""
+}
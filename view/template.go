@@ -5,6 +5,9 @@ import (
 	"html/template"
 	"log"
 	"strings"
+
+	"github.com/nabilulilalbab/welcomesite/models"
+	"github.com/nabilulilalbab/welcomesite/validation"
 )
 
 //go:embed templates/**/*.html
@@ -20,6 +23,33 @@ var funcMap = template.FuncMap{
 	"mod": func(i, j int) int {
 		return i % j
 	},
+	"join": strings.Join,
+	"tagNames": func(tags []models.Tag) string {
+		return strings.Join(models.TagNames(tags), ", ")
+	},
+	"card": func(task models.Task, workflow *models.Workflow) TaskCard {
+		return TaskCard{Task: task, Workflow: workflow}
+	},
+}
+
+// TaskCard adalah data template "task_card".
+type TaskCard struct {
+	models.Task
+	Workflow *models.Workflow
+}
+
+// TaskForm adalah isi modal tambah atau edit task yang ditolak. Halaman
+// menerimanya sebagai JSON lalu membuka modal itu lagi dengan input
+// pengguna dan pesan error per field.
+type TaskForm struct {
+	// Modal adalah "add" atau "edit".
+	Modal  string `json:"modal"`
+	TaskID uint   `json:"task_id,omitempty"`
+	// Status adalah status task yang tersimpan, dasar pilihan status di
+	// modal edit.
+	Status string            `json:"status,omitempty"`
+	Values map[string]string `json:"values"`
+	Errors validation.Errors `json:"errors"`
 }
 
 func ParseTemplates() *template.Template {